@@ -0,0 +1,231 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+
+	"github.com/go-compcont/compcont-core"
+	"github.com/go-compcont/compcont-std/container"
+	"github.com/go-compcont/compcont-std/graph"
+	"github.com/go-compcont/compcont-std/metrics"
+	"github.com/go-compcont/compcont-std/schema"
+	"github.com/go-compcont/compcont-std/validate"
+)
+
+func runValidate(args []string) (err error) {
+	file, err := parseFileArgs(newFlagSet("validate"), args)
+	if err != nil {
+		return
+	}
+	v := validate.New(registry())
+	_, err = v.ValidateFile(file)
+	for _, w := range v.Warnings() {
+		fmt.Fprintf(os.Stderr, "warning: %s\n", w)
+	}
+	if err != nil {
+		return
+	}
+	fmt.Printf("%s: ok\n", file)
+	return
+}
+
+func runTree(args []string) (err error) {
+	file, err := parseFileArgs(newFlagSet("tree"), args)
+	if err != nil {
+		return
+	}
+	root, err := validate.New(registry()).ValidateFile(file)
+	if err != nil {
+		return
+	}
+	fmt.Println(file)
+	printTree(os.Stdout, root, "")
+	return
+}
+
+func printTree(w io.Writer, c *validate.Container, indent string) {
+	for i, comp := range c.Components {
+		branch, next := "├── ", "│   "
+		if i == len(c.Components)-1 {
+			branch, next = "└── ", "    "
+		}
+		var sb strings.Builder
+		sb.WriteString(string(comp.Config.Name))
+		if comp.Config.Type != "" {
+			fmt.Fprintf(&sb, " [%s]", comp.Config.Type)
+		}
+		if comp.Target != nil {
+			fmt.Fprintf(&sb, " -> %s", container.Path(comp.Target.AbsolutePath()))
+		}
+		if len(comp.Config.Deps) > 0 {
+			deps := make([]string, 0, len(comp.Config.Deps))
+			for _, d := range comp.Config.Deps {
+				deps = append(deps, string(d))
+			}
+			fmt.Fprintf(&sb, " deps: %s", strings.Join(deps, ", "))
+		}
+		fmt.Fprintln(w, indent+branch+sb.String())
+		for _, ref := range comp.Refs {
+			if ref.Target != nil {
+				fmt.Fprintf(w, "%s%s  %s -> %s\n", indent, next, ref.Field, container.Path(ref.Target.AbsolutePath()))
+			}
+		}
+		if comp.Children != nil {
+			printTree(w, comp.Children, indent+next)
+		}
+	}
+}
+
+func runGraph(args []string) (err error) {
+	fs := newFlagSet("graph")
+	format := fs.String("format", "dot", "output format: dot|mermaid|json")
+	file, err := parseFileArgs(fs, args)
+	if err != nil {
+		return
+	}
+	root, err := validate.New(registry()).ValidateFile(file)
+	if err != nil {
+		return
+	}
+	g := graph.FromValidated(root)
+	switch *format {
+	case "dot":
+		return graph.WriteDOT(os.Stdout, g)
+	case "mermaid":
+		return graph.WriteMermaid(os.Stdout, g)
+	case "json":
+		return graph.WriteJSON(os.Stdout, g)
+	default:
+		return fmt.Errorf("%w: unknown format %q", errUsage, *format)
+	}
+}
+
+func runTypes(args []string) (err error) {
+	fs := newFlagSet("types")
+	withSchema := fs.Bool("schema", false, "print config schema of each type as json")
+	if err = fs.Parse(args); err != nil {
+		return fmt.Errorf("%w: %w", errUsage, err)
+	}
+	types := sortedTypes()
+	if fs.NArg() > 0 {
+		types = types[:0]
+		for _, t := range fs.Args() {
+			if _, err = registry().GetFactory(compcont.ComponentTypeID(t)); err != nil {
+				return fmt.Errorf("%w, registered types: %s", err, typeList(sortedTypes()))
+			}
+			types = append(types, compcont.ComponentTypeID(t))
+		}
+	}
+
+	if !*withSchema {
+		for _, t := range types {
+			f, _ := registry().GetFactory(t)
+			configType := "-"
+			if rt, ok := schema.ConfigTypeOf(f); ok {
+				configType = rt.String()
+			}
+			fmt.Printf("%-32s %s\n", t, configType)
+		}
+		return
+	}
+
+	g := schema.NewGenerator(registry())
+	schemas := map[compcont.ComponentTypeID]*schema.Schema{}
+	for _, t := range types {
+		if schemas[t], err = g.TypeSchema(t); err != nil {
+			return
+		}
+	}
+	return printJSON(schemas)
+}
+
+func runSchema(args []string) (err error) {
+	fs := newFlagSet("schema")
+	if err = fs.Parse(args); err != nil || fs.NArg() != 0 {
+		return errUsage
+	}
+	s, err := schema.NewGenerator(registry()).ComponentListSchema()
+	if err != nil {
+		return
+	}
+	return printJSON(s)
+}
+
+func printJSON(v any) error {
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+func runRun(args []string) (err error) {
+	fs := newFlagSet("run")
+	noValidate := fs.Bool("no-validate", false, "skip static validation before building")
+	graphFile := fs.String("graph", "", "write the built component graph with build timings to file (.dot|.mmd|.json)")
+	file, err := parseFileArgs(fs, args)
+	if err != nil {
+		return
+	}
+	if !*noValidate {
+		if _, err = validate.New(registry()).ValidateFile(file); err != nil {
+			return
+		}
+	}
+	components, err := container.LoadFile(file)
+	if err != nil {
+		return
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	timings := graph.NewTimings()
+	// 构造耗时同时输出到std.metrics的默认registry
+	cc := compcont.NewComponentContainer(compcont.WithFactoryRegistry(metrics.DefaultRegistry.FactoryRegistry(timings.Registry(registry()))))
+	loaded := make(chan error, 1)
+	go func() {
+		loaded <- cc.LoadNamedComponents(components)
+	}()
+	select {
+	case err = <-loaded:
+		if err != nil {
+			return
+		}
+	case <-ctx.Done():
+		// 构造过程中收到退出信号，部分组件可能仍在构造中，直接退出
+		err = fmt.Errorf("interrupted while loading components: %w", ctx.Err())
+		return
+	}
+	fmt.Fprintf(os.Stderr, "%s: %d components loaded, waiting for signal\n", file, len(cc.LoadedComponentNames()))
+	if *graphFile != "" {
+		if err = writeGraphFile(*graphFile, graph.FromContainer(cc, graph.WithTimings(timings))); err != nil {
+			return
+		}
+	}
+
+	<-ctx.Done()
+	stop()
+	return container.DestroyComponents(cc)
+}
+
+func writeGraphFile(file string, g *graph.Graph) (err error) {
+	f, err := os.Create(file)
+	if err != nil {
+		return
+	}
+	defer f.Close()
+	switch {
+	case strings.HasSuffix(file, ".dot"):
+		err = graph.WriteDOT(f, g)
+	case strings.HasSuffix(file, ".mmd"):
+		err = graph.WriteMermaid(f, g)
+	default:
+		err = graph.WriteJSON(f, g)
+	}
+	return
+}
@@ -0,0 +1,102 @@
+// compcont 组件配置命令行工具，内置std中的全部组件
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/go-compcont/compcont-core"
+	_ "github.com/go-compcont/compcont-std"
+)
+
+type command struct {
+	name  string
+	usage string
+	run   func(args []string) error
+}
+
+var commands = []command{
+	{"validate", "validate <file>            校验配置文件，不构造任何组件", runValidate},
+	{"tree", "tree <file>                打印解析后的组件依赖树", runTree},
+	{"graph", "graph [-format dot|mermaid|json] <file>  输出组件依赖图", runGraph},
+	{"types", "types [-schema] [type...]  列出已注册的组件类型及其配置Schema", runTypes},
+	{"schema", "schema                     输出组件配置列表的JSON Schema", runSchema},
+	{"run", "run [-no-validate] [-graph out] <file>  构造容器并运行，直到收到退出信号", runRun},
+}
+
+// 参数错误，需要打印用法
+var errUsage = errors.New("usage error")
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: compcont <command> [arguments]")
+	fmt.Fprintln(os.Stderr)
+	fmt.Fprintln(os.Stderr, "commands:")
+	for _, c := range commands {
+		fmt.Fprintln(os.Stderr, "  "+c.usage)
+	}
+}
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+		os.Exit(2)
+	}
+	idx := slices.IndexFunc(commands, func(c command) bool { return c.name == os.Args[1] })
+	if idx < 0 {
+		fmt.Fprintf(os.Stderr, "unknown command %q\n", os.Args[1])
+		usage()
+		os.Exit(2)
+	}
+	err := commands[idx].run(os.Args[2:])
+	switch {
+	case err == nil:
+	case errors.Is(err, errUsage):
+		fmt.Fprintln(os.Stderr, "usage: compcont "+commands[idx].usage)
+		os.Exit(2)
+	default:
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
+
+func newFlagSet(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.Usage = func() {}
+	return fs
+}
+
+// 解析参数，要求剩余恰好一个文件参数
+func parseFileArgs(fs *flag.FlagSet, args []string) (file string, err error) {
+	if err = fs.Parse(args); err != nil {
+		err = fmt.Errorf("%w: %w", errUsage, err)
+		return
+	}
+	if fs.NArg() != 1 {
+		err = errUsage
+		return
+	}
+	file = fs.Arg(0)
+	return
+}
+
+func registry() compcont.IFactoryRegistry {
+	return compcont.DefaultFactoryRegistry
+}
+
+func sortedTypes() []compcont.ComponentTypeID {
+	types := registry().RegisteredComponentTypes()
+	slices.Sort(types)
+	return types
+}
+
+func typeList(types []compcont.ComponentTypeID) string {
+	parts := make([]string, 0, len(types))
+	for _, t := range types {
+		parts = append(parts, string(t))
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,322 @@
+package compcontzap
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"reflect"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-compcont/compcont-core"
+	"github.com/go-resty/resty/v2"
+	"go.uber.org/zap/zapcore"
+)
+
+// 转发给notifier的告警
+type Alert struct {
+	Time       time.Time      `json:"time"`
+	Level      string         `json:"level"`
+	Logger     string         `json:"logger,omitempty"`
+	Message    string         `json:"message"`
+	Caller     string         `json:"caller,omitempty"`
+	Stack      string         `json:"stack,omitempty"`
+	Fields     map[string]any `json:"fields,omitempty"`
+	Suppressed int            `json:"suppressed,omitempty"` // 上次告警之后被去重或限流的相同告警条数
+	Summary    bool           `json:"summary,omitempty"`    // 进入新的interval时仅汇总被抑制的条数，没有对应的日志
+}
+
+// 告警的发送方，例如webhook、IM机器人
+type INotifier interface {
+	Notify(ctx context.Context, alert Alert) error
+}
+
+type NotifierFunc func(ctx context.Context, alert Alert) error
+
+func (fn NotifierFunc) Notify(ctx context.Context, alert Alert) error {
+	return fn(ctx, alert)
+}
+
+// 将达到级别的日志转发给notifier，例如
+//
+//	alerts:
+//	  - level: error
+//	    notifier: { refer: ../webhook }
+//	    dedup_window: 5m
+//	    max_alerts: 10
+//	    interval: 1m
+type AlertConfig struct {
+	Level       string                                         `ccf:"level"`        // 默认error
+	Notifier    *compcont.TypedComponentConfig[any, INotifier] `ccf:"notifier"`     // 必填
+	DedupWindow time.Duration                                  `ccf:"dedup_window"` // 相同级别、消息与调用位置的日志在窗口内只告警一次，默认1m
+	MaxAlerts   int                                            `ccf:"max_alerts"`   // 每个interval内最多发送的告警数，默认10
+	Interval    time.Duration                                  `ccf:"interval"`     // 默认1m
+	Stacktrace  bool                                           `ccf:"stacktrace"`   // 日志未带堆栈时补充堆栈
+	Timeout     time.Duration                                  `ccf:"timeout"`      // 单次发送超时，默认10s
+	QueueSize   int                                            `ccf:"queue_size"`   // 待发送队列长度，队列满时丢弃，默认100
+}
+
+type alertKey struct {
+	level   zapcore.Level
+	message string
+	caller  string
+}
+
+type alertState struct {
+	logger     string
+	lastSeen   time.Time
+	lastSent   time.Time
+	suppressed int
+}
+
+type alerter struct {
+	cfg      AlertConfig
+	level    zapcore.Level
+	notifier INotifier
+
+	mu          sync.Mutex
+	seen        map[alertKey]*alertState
+	windowStart time.Time
+	sent        int
+
+	queue chan Alert
+	wg    sync.WaitGroup
+	once  sync.Once
+}
+
+// NewAlertCore 构造转发告警的core，通常通过std.logger.zap的alerts配置使用，
+// 返回的stop函数等待队列中的告警发送完毕
+func NewAlertCore(cfg AlertConfig, notifier INotifier) (core zapcore.Core, stop func(), err error) {
+	a := &alerter{cfg: cfg, notifier: notifier, level: zapcore.ErrorLevel, seen: map[alertKey]*alertState{}}
+	if cfg.Level != "" {
+		if a.level, err = zapcore.ParseLevel(cfg.Level); err != nil {
+			return
+		}
+	}
+	if a.cfg.DedupWindow <= 0 {
+		a.cfg.DedupWindow = time.Minute
+	}
+	if a.cfg.MaxAlerts <= 0 {
+		a.cfg.MaxAlerts = 10
+	}
+	if a.cfg.Interval <= 0 {
+		a.cfg.Interval = time.Minute
+	}
+	if a.cfg.Timeout <= 0 {
+		a.cfg.Timeout = 10 * time.Second
+	}
+	if a.cfg.QueueSize <= 0 {
+		a.cfg.QueueSize = 100
+	}
+	a.queue = make(chan Alert, a.cfg.QueueSize)
+	a.wg.Add(1)
+	go a.run(a.queue)
+	return &alertCore{alerter: a}, a.stop, nil
+}
+
+func (a *alerter) run(queue <-chan Alert) {
+	defer a.wg.Done()
+	for alert := range queue {
+		ctx, cancel := context.WithTimeout(context.Background(), a.cfg.Timeout)
+		if err := a.notifier.Notify(ctx, alert); err != nil {
+			// 不能通过logger输出，避免告警失败再次触发告警
+			fmt.Fprintf(os.Stderr, "%v compcontzap alert notify error: %v\n", time.Now(), err)
+		}
+		cancel()
+	}
+}
+
+func (a *alerter) stop() {
+	a.once.Do(func() {
+		a.mu.Lock()
+		close(a.queue)
+		a.queue = nil
+		a.mu.Unlock()
+		a.wg.Wait()
+	})
+}
+
+// 去重与限流，允许发送时返回之前被抑制的条数，必须持有mu
+func (a *alerter) allow(key alertKey, logger string, now time.Time) (suppressed int, ok bool) {
+	st, exists := a.seen[key]
+	if !exists {
+		st = &alertState{}
+		a.seen[key] = st
+	}
+	st.logger, st.lastSeen = logger, now
+	if exists && now.Sub(st.lastSent) < a.cfg.DedupWindow {
+		st.suppressed++
+		return
+	}
+	if now.Sub(a.windowStart) >= a.cfg.Interval {
+		a.windowStart, a.sent = now, 0
+		a.flush(key, now)
+	}
+	if a.sent >= a.cfg.MaxAlerts {
+		st.suppressed++
+		return
+	}
+	a.sent++
+	suppressed, st.suppressed, st.lastSent = st.suppressed, 0, now
+	return suppressed, true
+}
+
+// 进入新的interval时为去重窗口已过、仍有抑制条数的告警发送汇总(计入本interval的max_alerts)，
+// 并清理最近出现与发送都已超过去重窗口的记录，无法发送汇总的抑制条数随记录一起丢弃。必须持有mu
+func (a *alerter) flush(current alertKey, now time.Time) {
+	for k, s := range a.seen {
+		if k == current {
+			continue
+		}
+		if s.suppressed > 0 && now.Sub(s.lastSent) >= a.cfg.DedupWindow && a.sent < a.cfg.MaxAlerts {
+			a.sent++
+			a.enqueue(Alert{
+				Time:       now,
+				Level:      k.level.String(),
+				Logger:     s.logger,
+				Message:    k.message,
+				Caller:     k.caller,
+				Suppressed: s.suppressed,
+				Summary:    true,
+			})
+			s.suppressed, s.lastSent = 0, now
+		}
+		if now.Sub(s.lastSeen) >= a.cfg.DedupWindow && now.Sub(s.lastSent) >= a.cfg.DedupWindow {
+			delete(a.seen, k)
+		}
+	}
+}
+
+// 队列满时丢弃，必须持有mu
+func (a *alerter) enqueue(alert Alert) {
+	select {
+	case a.queue <- alert:
+	default:
+	}
+}
+
+func (a *alerter) write(ent zapcore.Entry, fields []zapcore.Field) {
+	var caller string
+	if ent.Caller.Defined {
+		caller = ent.Caller.String()
+	}
+	key := alertKey{level: ent.Level, message: ent.Message, caller: caller}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	if a.queue == nil {
+		return
+	}
+	suppressed, ok := a.allow(key, ent.LoggerName, ent.Time)
+	if !ok {
+		return
+	}
+
+	enc := zapcore.NewMapObjectEncoder()
+	for _, f := range fields {
+		f.AddTo(enc)
+	}
+	alert := Alert{
+		Time:       ent.Time,
+		Level:      ent.Level.String(),
+		Logger:     ent.LoggerName,
+		Message:    ent.Message,
+		Caller:     caller,
+		Stack:      ent.Stack,
+		Fields:     enc.Fields,
+		Suppressed: suppressed,
+	}
+	if alert.Stack == "" && a.cfg.Stacktrace {
+		alert.Stack = callerStack()
+	}
+	a.enqueue(alert)
+}
+
+var packagePath = reflect.TypeOf(alerter{}).PkgPath()
+
+// 从日志调用方开始的调用栈，格式与zap的stacktrace一致。
+// 经过的zap、slog与本包的帧数随logger的包装方式变化，按函数所在的包跳过而不是固定层数
+func callerStack() string {
+	pcs := make([]uintptr, 64)
+	frames := runtime.CallersFrames(pcs[:runtime.Callers(2, pcs)])
+	var b strings.Builder
+	skipping := true
+	for {
+		frame, more := frames.Next()
+		skipping = skipping && isLoggingFrame(frame)
+		if !skipping {
+			if b.Len() > 0 {
+				b.WriteByte('\n')
+			}
+			fmt.Fprintf(&b, "%s\n\t%s:%d", frame.Function, frame.File, frame.Line)
+		}
+		if !more {
+			break
+		}
+	}
+	return b.String()
+}
+
+func isLoggingFrame(frame runtime.Frame) bool {
+	if strings.HasSuffix(frame.File, "_test.go") {
+		return false
+	}
+	for _, prefix := range []string{"go.uber.org/zap.", "go.uber.org/zap/", "log.", "log/slog.", packagePath + "."} {
+		if strings.HasPrefix(frame.Function, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+type alertCore struct {
+	alerter *alerter
+	fields  []zapcore.Field
+}
+
+func (c *alertCore) Enabled(lvl zapcore.Level) bool {
+	return lvl >= c.alerter.level
+}
+
+func (c *alertCore) With(fields []zapcore.Field) zapcore.Core {
+	return &alertCore{alerter: c.alerter, fields: append(c.fields[:len(c.fields):len(c.fields)], fields...)}
+}
+
+func (c *alertCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+func (c *alertCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	c.alerter.write(ent, append(c.fields[:len(c.fields):len(c.fields)], fields...))
+	return nil
+}
+
+func (c *alertCore) Sync() error {
+	return nil
+}
+
+// 通过HTTP POST JSON发送告警
+type WebhookNotifier struct {
+	URL     string
+	Headers map[string]string
+	Client  *resty.Client
+}
+
+func (n *WebhookNotifier) Notify(ctx context.Context, alert Alert) error {
+	resp, err := n.Client.R().
+		SetContext(ctx).
+		SetHeaders(n.Headers).
+		SetBody(alert).
+		Post(n.URL)
+	if err != nil {
+		return err
+	}
+	if resp.IsError() {
+		return fmt.Errorf("webhook %s responded %s", n.URL, resp.Status())
+	}
+	return nil
+}
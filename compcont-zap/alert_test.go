@@ -0,0 +1,119 @@
+package compcontzap
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestAlertCore(t *testing.T) {
+	var mu sync.Mutex
+	var alerts []Alert
+	core, stop, err := NewAlertCore(AlertConfig{DedupWindow: time.Hour, MaxAlerts: 2}, NotifierFunc(func(ctx context.Context, alert Alert) error {
+		mu.Lock()
+		defer mu.Unlock()
+		alerts = append(alerts, alert)
+		return nil
+	}))
+	assert.NoError(t, err)
+
+	encoding := "json"
+	logger, err := NewWithCores(Config{BaseConfig: "production", ExtraConfig: ExtraConfig{Encoding: &encoding, OutputPaths: []string{"memory://test-alert"}}}, core)
+	assert.NoError(t, err)
+	assert.NoError(t, OnClose(logger, stop))
+
+	logger.Warn("not alerted")
+	for range 3 {
+		logger.Error("db down", zap.String("db", "main"))
+	}
+	logger.Error("disk full")
+	logger.Error("third distinct, rate limited")
+	assert.NoError(t, Close(logger))
+
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Len(t, alerts, 2)
+	assert.Equal(t, "db down", alerts[0].Message)
+	assert.Equal(t, "main", alerts[0].Fields["db"])
+	assert.NotEmpty(t, alerts[0].Stack)
+	assert.Equal(t, "disk full", alerts[1].Message)
+}
+
+func TestAlertSummaryAndEviction(t *testing.T) {
+	var mu sync.Mutex
+	var alerts []Alert
+	core, stop, err := NewAlertCore(AlertConfig{DedupWindow: time.Minute, MaxAlerts: 10, Interval: time.Minute}, NotifierFunc(func(ctx context.Context, alert Alert) error {
+		mu.Lock()
+		defer mu.Unlock()
+		alerts = append(alerts, alert)
+		return nil
+	}))
+	if !assert.NoError(t, err) {
+		return
+	}
+	a := core.(*alertCore).alerter
+	start := time.Unix(0, 0)
+	write := func(msg string, at time.Duration) {
+		a.write(zapcore.Entry{Level: zapcore.ErrorLevel, Message: msg, Time: start.Add(at)}, nil)
+	}
+
+	write("db down", 0)
+	write("db down", 10*time.Second)
+	write("db down", 20*time.Second)
+	// 新的interval，db down的抑制条数作为汇总发送
+	write("disk full", 2*time.Minute)
+	// 之后很久没有再出现，db down与disk full的记录被清理
+	write("other", 10*time.Minute)
+	a.mu.Lock()
+	assert.Len(t, a.seen, 1)
+	a.mu.Unlock()
+	stop()
+
+	mu.Lock()
+	defer mu.Unlock()
+	if !assert.Len(t, alerts, 4) {
+		return
+	}
+	assert.Equal(t, "db down", alerts[0].Message)
+	assert.False(t, alerts[0].Summary)
+	assert.Equal(t, "db down", alerts[1].Message)
+	assert.True(t, alerts[1].Summary)
+	assert.Equal(t, 2, alerts[1].Suppressed)
+	assert.Equal(t, "disk full", alerts[2].Message)
+	assert.Equal(t, "other", alerts[3].Message)
+}
+
+func TestAlertStacktrace(t *testing.T) {
+	alerts := make(chan Alert, 4)
+	core, stop, err := NewAlertCore(AlertConfig{Level: "warn", Stacktrace: true}, NotifierFunc(func(ctx context.Context, alert Alert) error {
+		alerts <- alert
+		return nil
+	}))
+	if !assert.NoError(t, err) {
+		return
+	}
+	logger, err := NewWithCores(Config{BaseConfig: "production", ExtraConfig: ExtraConfig{OutputPaths: []string{"memory://test-alert-stack"}}}, core)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.NoError(t, OnClose(logger, stop))
+
+	// 日志未带堆栈时补充的堆栈从调用方开始，zap或slog包装logger时也一样
+	logger.Warn("zap warn")
+	NewSlog(logger.Named("slog"), nil).Warn("slog warn")
+	assert.NoError(t, Close(logger))
+
+	for _, msg := range []string{"zap warn", "slog warn"} {
+		alert := <-alerts
+		assert.Equal(t, msg, alert.Message)
+		first, _, _ := strings.Cut(alert.Stack, "\n\t")
+		assert.Equal(t, "github.com/go-compcont/compcont-std/compcont-zap.TestAlertStacktrace", first, msg)
+		assert.Contains(t, alert.Stack, "compcont-zap/alert_test.go:", msg)
+	}
+}
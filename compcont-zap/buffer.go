@@ -0,0 +1,173 @@
+package compcontzap
+
+import (
+	"bufio"
+	"fmt"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+const (
+	OverflowBlock = "block" // 队列满时阻塞写入
+	OverflowDrop  = "drop"  // 队列满时丢弃，计入DropStats.Overflowed
+)
+
+// 异步缓冲写入，日志先进入有界队列，由后台goroutine写入缓冲区，
+// 缓冲区满或到达flush_interval时写入sink，Sync(包括logger关闭时)会等待队列与缓冲区全部写入
+//
+//	buffer: { size: 262144, flush_interval: 1s, queue_size: 1024, overflow: drop }
+type BufferConfig struct {
+	Size          int           `ccf:"size" json:"size" yaml:"size"`                               // 缓冲区字节数，默认256KiB
+	FlushInterval time.Duration `ccf:"flush_interval" json:"flush_interval" yaml:"flush_interval"` // 默认1s
+	QueueSize     int           `ccf:"queue_size" json:"queue_size" yaml:"queue_size"`             // 队列的日志条数，默认1024
+	Overflow      string        `ccf:"overflow" json:"overflow" yaml:"overflow"`                   // block(默认)或drop
+}
+
+type asyncRequest struct {
+	data []byte
+	done chan error // 非空表示Sync请求
+}
+
+type asyncWriteSyncer struct {
+	out     zapcore.WriteSyncer
+	drop    bool
+	dropped *DropStats
+
+	queue   chan asyncRequest
+	closed  chan struct{}
+	mu      sync.RWMutex // 写锁保护stopped，入队时持有读锁
+	stopped bool
+	wg      sync.WaitGroup
+}
+
+func (c *BufferConfig) wrap(out zapcore.WriteSyncer, dropped *DropStats) (ws *asyncWriteSyncer, err error) {
+	size, interval, queueSize := c.Size, c.FlushInterval, c.QueueSize
+	if size <= 0 {
+		size = 256 * 1024
+	}
+	if interval <= 0 {
+		interval = time.Second
+	}
+	if queueSize <= 0 {
+		queueSize = 1024
+	}
+	ws = &asyncWriteSyncer{
+		out:     out,
+		dropped: dropped,
+		queue:   make(chan asyncRequest, queueSize),
+		closed:  make(chan struct{}),
+	}
+	switch c.Overflow {
+	case "", OverflowBlock:
+	case OverflowDrop:
+		ws.drop = true
+	default:
+		return nil, fmt.Errorf("unsupported overflow policy: %s", c.Overflow)
+	}
+	ws.wg.Add(1)
+	go ws.run(bufio.NewWriterSize(out, size), interval)
+	return
+}
+
+func (w *asyncWriteSyncer) run(buf *bufio.Writer, interval time.Duration) {
+	defer w.wg.Done()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	write := func(req asyncRequest) {
+		if req.done == nil {
+			_, _ = buf.Write(req.data)
+			return
+		}
+		err := buf.Flush()
+		if err == nil {
+			err = w.out.Sync()
+		}
+		req.done <- err
+	}
+	for {
+		select {
+		case req := <-w.queue:
+			write(req)
+		case <-ticker.C:
+			_ = buf.Flush()
+		case <-w.closed:
+			// 写完队列中剩余的日志
+			for {
+				select {
+				case req := <-w.queue:
+					write(req)
+				default:
+					_ = buf.Flush()
+					return
+				}
+			}
+		}
+	}
+}
+
+func (w *asyncWriteSyncer) Write(p []byte) (n int, err error) {
+	// 持有读锁入队，保证stop之后不会再有日志进入已经写完的队列
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	if w.stopped {
+		return w.out.Write(p)
+	}
+	req := asyncRequest{data: append([]byte(nil), p...)}
+	if w.drop {
+		select {
+		case w.queue <- req:
+		default:
+			w.dropped.overflowed.Add(1)
+		}
+		return len(p), nil
+	}
+	w.queue <- req
+	return len(p), nil
+}
+
+func (w *asyncWriteSyncer) Sync() error {
+	w.mu.RLock()
+	defer w.mu.RUnlock()
+	if w.stopped {
+		return w.out.Sync()
+	}
+	done := make(chan error, 1)
+	w.queue <- asyncRequest{done: done}
+	return <-done
+}
+
+// 停止后台goroutine并写入剩余日志，之后的写入直接写到sink
+func (w *asyncWriteSyncer) stop() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.stopped {
+		return
+	}
+	w.stopped = true
+	close(w.closed)
+	w.wg.Wait()
+	_ = w.out.Sync()
+}
+
+// 打开paths对应的sink，buffer非空时包装为异步缓冲写入
+func openSink(paths []string, buffer *BufferConfig, dropped *DropStats) (sink zapcore.WriteSyncer, closeFunc func(), err error) {
+	sink, closeFunc, err = zap.Open(paths...)
+	if err != nil || buffer == nil {
+		return
+	}
+	ws, err := buffer.wrap(sink, dropped)
+	if err != nil {
+		closeFunc()
+		return
+	}
+	closeSink := closeFunc
+	sink, closeFunc = ws, func() {
+		ws.stop()
+		closeSink()
+	}
+	return
+}
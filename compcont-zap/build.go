@@ -0,0 +1,128 @@
+package compcontzap
+
+import (
+	"fmt"
+	"sort"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+func newEncoder(encoding string, encoderConfig zapcore.EncoderConfig) (zapcore.Encoder, error) {
+	switch encoding {
+	case "json":
+		return zapcore.NewJSONEncoder(encoderConfig), nil
+	case "console":
+		return zapcore.NewConsoleEncoder(encoderConfig), nil
+	case "":
+		return nil, fmt.Errorf("encoding is empty")
+	default:
+		return nil, fmt.Errorf("unsupported encoding: %s", encoding)
+	}
+}
+
+// 按照zap.Config构造core，与zap.Config.Build的行为一致，但级别由enabler决定，
+// 配置了cores时构造多个core并通过zapcore.NewTee组合，attached为NewWithCores附加的core，
+// 额外返回sink的关闭函数，以便重建core时回收旧的sink
+func buildCore(cfg zap.Config, extra Config, attached []zapcore.Core, enabler zapcore.LevelEnabler, dropped *DropStats) (core zapcore.Core, errSink zapcore.WriteSyncer, closeFunc func(), err error) {
+	// 按构造的逆序关闭，先停止包装的core再关闭sink
+	var closeFuncs []func()
+	closeAll := func() {
+		for i := len(closeFuncs) - 1; i >= 0; i-- {
+			closeFuncs[i]()
+		}
+	}
+	defer func() {
+		if err != nil {
+			closeAll()
+			return
+		}
+		closeFunc = closeAll
+	}()
+	cores := extra.Cores
+	redact, err := newRedactor(extra.ExtraConfig.Redact)
+	if err != nil {
+		return
+	}
+
+	errSink, closeErr, err := zap.Open(cfg.ErrorOutputPaths...)
+	if err != nil {
+		return
+	}
+	closeFuncs = append(closeFuncs, closeErr)
+
+	if len(cores) == 0 {
+		var enc zapcore.Encoder
+		if enc, err = newEncoder(cfg.Encoding, cfg.EncoderConfig); err != nil {
+			return
+		}
+		c, closeOut, err1 := newSinkCore(enc, cfg.OutputPaths, extra.ExtraConfig.Buffer, enabler, dropped)
+		if err1 != nil {
+			err = err1
+			return
+		}
+		closeFuncs = append(closeFuncs, closeOut)
+		core = redact.wrap(c)
+	} else {
+		tee := make([]zapcore.Core, 0, len(cores))
+		for i := range cores {
+			c, closeOut, err1 := cores[i].build(cfg, extra.ExtraConfig.Buffer, enabler, dropped)
+			if err1 != nil {
+				err = fmt.Errorf("cores[%d]: %w", i, err1)
+				return
+			}
+			closeFuncs = append(closeFuncs, closeOut)
+			tee = append(tee, redact.wrap(c))
+		}
+		core = zapcore.NewTee(tee...)
+	}
+	if len(attached) > 0 {
+		tee := []zapcore.Core{core}
+		for _, c := range attached {
+			tee = append(tee, redact.wrap(c))
+		}
+		core = zapcore.NewTee(tee...)
+	}
+
+	core, stop, err := wrapSampling(core, cfg, extra.ExtraConfig.Sampling, dropped)
+	if err != nil {
+		return
+	}
+	closeFuncs = append(closeFuncs, stop)
+	return
+}
+
+// logger级别的选项，与zap.Config.Build一致，不包含ErrorOutput与采样
+func buildOptions(cfg zap.Config) []zap.Option {
+	var opts []zap.Option
+
+	if cfg.Development {
+		opts = append(opts, zap.Development())
+	}
+
+	if !cfg.DisableCaller {
+		opts = append(opts, zap.AddCaller())
+	}
+
+	stackLevel := zap.ErrorLevel
+	if cfg.Development {
+		stackLevel = zap.WarnLevel
+	}
+	if !cfg.DisableStacktrace {
+		opts = append(opts, zap.AddStacktrace(stackLevel))
+	}
+
+	if len(cfg.InitialFields) > 0 {
+		fs := make([]zap.Field, 0, len(cfg.InitialFields))
+		keys := make([]string, 0, len(cfg.InitialFields))
+		for k := range cfg.InitialFields {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			fs = append(fs, zap.Any(k, cfg.InitialFields[k]))
+		}
+		opts = append(opts, zap.Fields(fs...))
+	}
+	return opts
+}
@@ -0,0 +1,151 @@
+// Package compcontzaptest 测试中捕获compcontzap logger的输出
+package compcontzaptest
+
+import (
+	"fmt"
+	"log/slog"
+	"strings"
+	"testing"
+
+	compcontzap "github.com/go-compcont/compcont-std/compcont-zap"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+type Option func(o *options)
+
+type options struct {
+	config    compcontzap.Config
+	noDefault bool
+}
+
+// WithConfig 使用指定的std.logger.zap配置，默认为debug级别且不输出到任何sink
+func WithConfig(cfg compcontzap.Config) Option {
+	return func(o *options) {
+		o.config = cfg
+	}
+}
+
+// NoDefault 不安装为compcontzap与slog的默认logger
+func NoDefault() Option {
+	return func(o *options) {
+		o.noDefault = true
+	}
+}
+
+// 捕获logger输出的所有日志
+type Observer struct {
+	logger *zap.Logger
+	logs   *observer.ObservedLogs
+}
+
+// New 构造由observer core捕获输出的compcontzap logger，默认在测试期间作为compcontzap与slog的默认logger，
+// 测试结束后恢复并关闭logger
+func New(t testing.TB, opts ...Option) *Observer {
+	t.Helper()
+	level, encoding := "debug", "json"
+	o := options{config: compcontzap.Config{
+		ExtraConfig: compcontzap.ExtraConfig{Level: &level, Encoding: &encoding},
+	}}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	o.config.Default = false
+
+	core, logs := observer.New(zapcore.DebugLevel)
+	logger, err := compcontzap.NewWithCores(o.config, core)
+	if err != nil {
+		t.Fatalf("create observed logger: %v", err)
+	}
+	if !o.noDefault {
+		// 保存原始的默认logger，未设置时恢复为nil而不是development配置的logger
+		previous, _ := compcontzap.LookupDefault()
+		previousSlog := slog.Default()
+		compcontzap.SetDefault(logger)
+		slog.SetDefault(compcontzap.NewSlog(logger, nil))
+		t.Cleanup(func() {
+			compcontzap.SetDefault(previous)
+			slog.SetDefault(previousSlog)
+		})
+	}
+	t.Cleanup(func() {
+		_ = compcontzap.Close(logger)
+	})
+	return &Observer{logger: logger, logs: logs}
+}
+
+func (o *Observer) Logger() *zap.Logger {
+	return o.logger
+}
+
+// Logs 所有捕获的日志，可使用observer.ObservedLogs的Filter系列方法筛选
+func (o *Observer) Logs() *observer.ObservedLogs {
+	return o.logs
+}
+
+// All 所有捕获的日志
+func (o *Observer) All() []observer.LoggedEntry {
+	return o.logs.All()
+}
+
+// Component 由绝对路径为path(例如"/a/b")的组件通过compcontzap.ComponentLogger输出的日志
+func (o *Observer) Component(path string) *observer.ObservedLogs {
+	return o.logs.FilterField(zap.String("component", path))
+}
+
+// Reset 清空已捕获的日志
+func (o *Observer) Reset() {
+	o.logs.TakeAll()
+}
+
+// AssertLogged 断言logs中存在级别与消息一致且包含所有fields的日志
+func AssertLogged(t testing.TB, logs *observer.ObservedLogs, level zapcore.Level, message string, fields ...zap.Field) bool {
+	t.Helper()
+	matched := logs.FilterLevelExact(level).FilterMessage(message)
+	for _, f := range fields {
+		matched = matched.FilterField(f)
+	}
+	if matched.Len() > 0 {
+		return true
+	}
+	t.Errorf("no %s entry with message %q and fields %v in %d captured entries:\n%s", level, message, fieldKeys(fields), logs.Len(), dump(logs))
+	return false
+}
+
+// AssertNotLogged 断言logs中不存在级别与消息一致的日志
+func AssertNotLogged(t testing.TB, logs *observer.ObservedLogs, level zapcore.Level, message string) bool {
+	t.Helper()
+	if n := logs.FilterLevelExact(level).FilterMessage(message).Len(); n > 0 {
+		t.Errorf("unexpected %d %s entries with message %q", n, level, message)
+		return false
+	}
+	return true
+}
+
+// AssertLogged 在所有捕获的日志中断言，见AssertLogged
+func (o *Observer) AssertLogged(t testing.TB, level zapcore.Level, message string, fields ...zap.Field) bool {
+	t.Helper()
+	return AssertLogged(t, o.logs, level, message, fields...)
+}
+
+// AssertNotLogged 在所有捕获的日志中断言，见AssertNotLogged
+func (o *Observer) AssertNotLogged(t testing.TB, level zapcore.Level, message string) bool {
+	t.Helper()
+	return AssertNotLogged(t, o.logs, level, message)
+}
+
+func fieldKeys(fields []zap.Field) (keys []string) {
+	for _, f := range fields {
+		keys = append(keys, f.Key)
+	}
+	return
+}
+
+func dump(logs *observer.ObservedLogs) string {
+	var b strings.Builder
+	for _, e := range logs.All() {
+		fmt.Fprintf(&b, "  %s %s %v\n", e.Level, e.Message, e.ContextMap())
+	}
+	return b.String()
+}
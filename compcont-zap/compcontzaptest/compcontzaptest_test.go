@@ -0,0 +1,31 @@
+package compcontzaptest
+
+import (
+	"testing"
+
+	compcontzap "github.com/go-compcont/compcont-std/compcont-zap"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestObserver(t *testing.T) {
+	before, set := compcontzap.LookupDefault()
+	t.Run("capture", func(t *testing.T) {
+		obs := New(t)
+		compcontzap.GetDefault().With(zap.String("component", "/a/b")).Info("built", zap.Int("n", 1))
+		compcontzap.GetDefault().Debug("other")
+
+		obs.AssertLogged(t, zapcore.InfoLevel, "built", zap.Int("n", 1))
+		obs.AssertNotLogged(t, zapcore.ErrorLevel, "built")
+		if n := obs.Component("/a/b").Len(); n != 1 {
+			t.Errorf("component entries: %d", n)
+		}
+		obs.Reset()
+		if len(obs.All()) != 0 {
+			t.Errorf("reset failed")
+		}
+	})
+	if after, ok := compcontzap.LookupDefault(); after != before || ok != set {
+		t.Errorf("default logger not restored")
+	}
+}
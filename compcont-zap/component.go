@@ -1,8 +1,18 @@
 package compcontzap
 
 import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"sync"
+
 	"github.com/go-compcont/compcont-core"
+	"github.com/go-compcont/compcont-std/metrics"
+	"github.com/go-compcont/compcont-std/reloading"
+	"github.com/go-resty/resty/v2"
 	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
 )
 
 const TypeID compcont.ComponentTypeID = "std.logger.zap"
@@ -10,19 +20,226 @@ const TypeID compcont.ComponentTypeID = "std.logger.zap"
 var factory compcont.IComponentFactory = &compcont.TypedSimpleComponentFactory[Config, *zap.Logger]{
 	TypeID: TypeID,
 	CreateInstanceFunc: func(ctx compcont.BuildContext, config Config) (instance *zap.Logger, err error) {
-		logger, err := New(config)
+		alertCores, stopAlerts, err := buildAlertCores(ctx, config.Alerts)
 		if err != nil {
 			return
 		}
+		var logger *zap.Logger
+		// 构造logger之后的任何错误都需要关闭logger，stopAlerts可以重复调用
+		defer func() {
+			if err == nil {
+				return
+			}
+			if logger != nil {
+				_ = Close(logger)
+			}
+			stopAlerts()
+		}()
+		attached := alertCores
+		var registry *metrics.Registry
+		if config.Metrics != nil {
+			registry = config.Metrics.MustLoadComponent(ctx.Container).Instance
+			attached = append(attached, NewMetricsCore(registry, ComponentPath(ctx)))
+		}
+
+		if config.Reloading == nil {
+			if logger, err = newLogger(config, ComponentPath(ctx), attached...); err != nil {
+				return
+			}
+			if err = OnClose(logger, stopAlerts); err != nil {
+				return
+			}
+		} else {
+			rc, err1 := config.Reloading.Build(ctx.Container)
+			if err1 != nil {
+				err = err1
+				return
+			}
+			loaded, err1 := rc.LoadConfig(context.Background())
+			if err1 != nil {
+				_ = rc.Close()
+				err = err1
+				return
+			}
+			loaded.Default = config.Default
+			loaded.SlogDefault = config.SlogDefault
+			loaded.DefaultPriority = config.DefaultPriority
+			loaded.ReplaceGlobals = config.ReplaceGlobals
+			if logger, err = newLogger(loaded, ComponentPath(ctx), attached...); err != nil {
+				_ = rc.Close()
+				return
+			}
+			if err = OnClose(logger, stopAlerts); err != nil {
+				_ = rc.Close()
+				return
+			}
+			id := rc.AddOnReloadingConfigListener(reloading.OnReloadingConfigListenerFunc[Config](func(ctx context.Context, cfg Config) error {
+				return Reload(logger, cfg)
+			}))
+			// 引用的Reloading只取消监听，内联的随logger关闭
+			closeReloading := func() {
+				rc.RemoveOnReloadingConfigListener(id)
+				_ = rc.Close()
+			}
+			if err = OnClose(logger, closeReloading); err != nil {
+				closeReloading()
+				return
+			}
+		}
+
+		if registry != nil {
+			unregister, err1 := registerDropMetrics(registry, ComponentPath(ctx), logger)
+			if err1 != nil {
+				err = err1
+				return
+			}
+			if err = OnClose(logger, unregister); err != nil {
+				unregister()
+				return
+			}
+		}
 		instance = logger
 		return
 	},
+	DestroyInstanceFunc: func(ctx compcont.BuildContext, instance *zap.Logger) (err error) {
+		return Close(instance)
+	},
 }
 
+func buildAlertCores(ctx compcont.BuildContext, configs []AlertConfig) (cores []zapcore.Core, stop func(), err error) {
+	var stops []func()
+	stop = func() {
+		for _, fn := range stops {
+			fn()
+		}
+	}
+	for i, cfg := range configs {
+		if cfg.Notifier == nil {
+			stop()
+			return nil, nil, fmt.Errorf("alerts[%d]: notifier is required", i)
+		}
+		notifier := cfg.Notifier.MustLoadComponent(ctx.Container).Instance
+		core, stopAlert, err1 := NewAlertCore(cfg, notifier)
+		if err1 != nil {
+			stop()
+			return nil, nil, fmt.Errorf("alerts[%d]: %w", i, err1)
+		}
+		cores = append(cores, core)
+		stops = append(stops, stopAlert)
+	}
+	return
+}
+
+const WebhookNotifierTypeID compcont.ComponentTypeID = "std.logger.zap.webhook-notifier"
+
+type WebhookNotifierConfig struct {
+	URL     string                                             `ccf:"url"`
+	Headers map[string]string                                  `ccf:"headers"`
+	Resty   *compcont.TypedComponentConfig[any, *resty.Client] `ccf:"resty"` // 不填使用默认resty
+}
+
+// 以JSON POST告警的notifier，用于alerts配置
+var webhookNotifierFactory compcont.IComponentFactory = &compcont.TypedSimpleComponentFactory[WebhookNotifierConfig, INotifier]{
+	TypeID: WebhookNotifierTypeID,
+	CreateInstanceFunc: func(ctx compcont.BuildContext, config WebhookNotifierConfig) (instance INotifier, err error) {
+		if config.URL == "" {
+			err = fmt.Errorf("url is required")
+			return
+		}
+		var client *resty.Client
+		if config.Resty != nil {
+			client = config.Resty.MustLoadComponent(ctx.Container).Instance
+		} else {
+			client = resty.New()
+		}
+		instance = &WebhookNotifier{URL: config.URL, Headers: config.Headers, Client: client}
+		return
+	},
+}
+
+const LevelHandlerTypeID compcont.ComponentTypeID = "std.logger.zap.level-handler"
+
+type LevelHandlerConfig struct {
+	Logger *compcont.TypedComponentConfig[any, *zap.Logger] `ccf:"logger"` // 不填使用默认logger
+}
+
+// 级别管理的HTTP接口，见LevelController.ServeHTTP
+var levelHandlerFactory compcont.IComponentFactory = &compcont.TypedSimpleComponentFactory[LevelHandlerConfig, http.Handler]{
+	TypeID: LevelHandlerTypeID,
+	CreateInstanceFunc: func(ctx compcont.BuildContext, config LevelHandlerConfig) (instance http.Handler, err error) {
+		var logger *zap.Logger
+		if config.Logger != nil {
+			logger = config.Logger.MustLoadComponent(ctx.Container).Instance
+		} else {
+			logger = GetDefault()
+		}
+		levels, err := Levels(logger)
+		if err != nil {
+			return
+		}
+		instance = levels
+		return
+	},
+}
+
+const SlogTypeID compcont.ComponentTypeID = "std.logger.slog"
+
+type SlogConfig struct {
+	Logger    *compcont.TypedComponentConfig[any, *zap.Logger] `ccf:"logger"`     // 不填使用默认logger
+	Name      string                                           `ccf:"name"`       // 不为空时使用logger.Named(name)
+	AddSource bool                                             `ccf:"add_source"` // 输出slog记录的调用位置
+	Default   bool                                             `ccf:"default"`    // 作为slog的默认logger
+}
+
+// 通过zap logger输出的*slog.Logger，见SlogHandler
+var slogFactory compcont.IComponentFactory = &compcont.TypedSimpleComponentFactory[SlogConfig, *slog.Logger]{
+	TypeID: SlogTypeID,
+	CreateInstanceFunc: func(ctx compcont.BuildContext, config SlogConfig) (instance *slog.Logger, err error) {
+		var logger *zap.Logger
+		if config.Logger != nil {
+			logger = config.Logger.MustLoadComponent(ctx.Container).Instance
+		} else {
+			logger = GetDefault()
+		}
+		if config.Name != "" {
+			logger = logger.Named(config.Name)
+		}
+		instance = NewSlog(logger, &SlogHandlerOptions{AddSource: config.AddSource})
+		if config.Default {
+			slogRestores.Store(instance, setSlogDefault(instance))
+		}
+		return
+	},
+	DestroyInstanceFunc: func(ctx compcont.BuildContext, instance *slog.Logger) (err error) {
+		if restore, ok := slogRestores.LoadAndDelete(instance); ok {
+			restore.(func())()
+		}
+		return
+	},
+}
+
+// 设为slog默认logger的组件实例到恢复函数的映射，销毁时恢复之前的默认logger
+var slogRestores sync.Map
+
 func MustRegister(registry compcont.IFactoryRegistry) {
 	compcont.MustRegister(registry, factory)
 }
 
+func MustRegisterLevelHandler(registry compcont.IFactoryRegistry) {
+	compcont.MustRegister(registry, levelHandlerFactory)
+}
+
+func MustRegisterSlog(registry compcont.IFactoryRegistry) {
+	compcont.MustRegister(registry, slogFactory)
+}
+
+func MustRegisterWebhookNotifier(registry compcont.IFactoryRegistry) {
+	compcont.MustRegister(registry, webhookNotifierFactory)
+}
+
 func init() {
 	MustRegister(compcont.DefaultFactoryRegistry)
+	MustRegisterLevelHandler(compcont.DefaultFactoryRegistry)
+	MustRegisterSlog(compcont.DefaultFactoryRegistry)
+	MustRegisterWebhookNotifier(compcont.DefaultFactoryRegistry)
 }
@@ -0,0 +1,34 @@
+package compcontzap
+
+import (
+	"github.com/go-compcont/compcont-core"
+	"github.com/go-compcont/compcont-std/container"
+	"go.uber.org/zap"
+)
+
+// ComponentPath 组件绝对路径的字符串形式，例如"/a/b"
+func ComponentPath(ctx compcont.BuildContext) string {
+	return container.Path(ctx.GetAbsolutePath())
+}
+
+// ComponentFields 标识当前构造中组件的字段
+func ComponentFields(ctx compcont.BuildContext) []zap.Field {
+	return []zap.Field{
+		zap.String("component", ComponentPath(ctx)),
+		zap.Stringer("component_type", ctx.Config.Type),
+	}
+}
+
+// ComponentLogger 在CreateInstanceFunc中获取当前组件的logger，附带组件的绝对路径与类型，
+// ref为组件配置中引用的logger，为空时使用默认logger
+//
+//	logger := compcontzap.ComponentLogger(ctx, cfg.Logger)
+func ComponentLogger(ctx compcont.BuildContext, ref *compcont.TypedComponentConfig[any, *zap.Logger]) *zap.Logger {
+	var logger *zap.Logger
+	if ref != nil {
+		logger = ref.MustLoadComponent(ctx.Container).Instance
+	} else {
+		logger = GetDefault()
+	}
+	return logger.With(ComponentFields(ctx)...)
+}
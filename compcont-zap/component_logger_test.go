@@ -0,0 +1,62 @@
+package compcontzap
+
+import (
+	"testing"
+
+	"github.com/go-compcont/compcont-core"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+func TestComponentLogger(t *testing.T) {
+	encoding := "json"
+	logger, err := New(Config{
+		BaseConfig:  "production",
+		ExtraConfig: ExtraConfig{Encoding: &encoding, OutputPaths: []string{"memory://test-component-logger"}},
+	})
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer Close(logger)
+	ring := GetRingBuffer("test-component-logger")
+	ring.Reset()
+
+	registry := compcont.NewFactoryRegistry()
+	compcont.MustRegister(registry, &compcont.TypedSimpleComponentFactory[struct{}, *zap.Logger]{
+		TypeID: "test.logger",
+		CreateInstanceFunc: func(ctx compcont.BuildContext, config struct{}) (*zap.Logger, error) {
+			return logger, nil
+		},
+	})
+	var fields []zap.Field
+	compcont.MustRegister(registry, &compcont.TypedSimpleComponentFactory[struct{}, any]{
+		TypeID: "test.user",
+		CreateInstanceFunc: func(ctx compcont.BuildContext, config struct{}) (instance any, err error) {
+			fields = ComponentFields(ctx)
+			ComponentLogger(ctx, &compcont.TypedComponentConfig[any, *zap.Logger]{Refer: "logger"}).Info("built")
+			return
+		},
+	})
+
+	root := compcont.NewComponentContainer(compcont.WithFactoryRegistry(registry))
+	sub := compcont.NewComponentContainer(
+		compcont.WithFactoryRegistry(registry),
+		compcont.WithParentContainer(root),
+		compcont.WithContext(compcont.BuildContext{Container: root, Config: compcont.ComponentConfig{Name: "sub"}}),
+	)
+	if !assert.NoError(t, sub.LoadNamedComponents([]compcont.ComponentConfig{
+		{Name: "logger", Type: "test.logger"},
+		{Name: "user", Type: "test.user", Deps: []compcont.ComponentName{"logger"}},
+	})) {
+		return
+	}
+
+	assert.Equal(t, []zap.Field{
+		zap.String("component", "/sub/user"),
+		zap.Stringer("component_type", compcont.ComponentTypeID("test.user")),
+	}, fields)
+	recent := ring.Recent(0)
+	if assert.Len(t, recent, 1) {
+		assert.Contains(t, recent[0], `"msg":"built","component":"/sub/user","component_type":"test.user"`)
+	}
+}
@@ -0,0 +1,108 @@
+package compcontzap
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"sync/atomic"
+
+	"go.uber.org/zap"
+)
+
+type ctxKeyFields struct{}
+type ctxKeyTenant struct{}
+type ctxKeyTrace struct{}
+
+// AddFields 在context上附加字段，FromContext返回的logger会带上这些字段
+func AddFields(ctx context.Context, fields ...zap.Field) context.Context {
+	existing, _ := ctx.Value(ctxKeyFields{}).([]zap.Field)
+	return context.WithValue(ctx, ctxKeyFields{}, append(slices.Clip(existing), fields...))
+}
+
+// 从context中提取字段，例如请求ID、租户、trace ID
+type FieldExtractor func(ctx context.Context) []zap.Field
+
+type extractorEntry struct {
+	fn FieldExtractor
+}
+
+var (
+	extractorsMu sync.Mutex
+	extractors   atomic.Pointer[[]*extractorEntry]
+)
+
+// RegisterFieldExtractor 注册字段提取器，FromContext按注册顺序调用，返回取消注册的函数
+func RegisterFieldExtractor(fn FieldExtractor) (remove func()) {
+	e := &extractorEntry{fn: fn}
+	extractorsMu.Lock()
+	defer extractorsMu.Unlock()
+	next := append(slices.Clone(loadExtractors()), e)
+	extractors.Store(&next)
+	return func() {
+		extractorsMu.Lock()
+		defer extractorsMu.Unlock()
+		next := slices.DeleteFunc(slices.Clone(loadExtractors()), func(item *extractorEntry) bool { return item == e })
+		extractors.Store(&next)
+	}
+}
+
+func loadExtractors() []*extractorEntry {
+	if p := extractors.Load(); p != nil {
+		return *p
+	}
+	return nil
+}
+
+// ContextFields 按照提取器与AddFields获取context中的所有字段
+func ContextFields(ctx context.Context) (fields []zap.Field) {
+	for _, e := range loadExtractors() {
+		fields = append(fields, e.fn(ctx)...)
+	}
+	added, _ := ctx.Value(ctxKeyFields{}).([]zap.Field)
+	return append(fields, added...)
+}
+
+// WithTenant 在context中保存租户，输出为tenant字段
+func WithTenant(ctx context.Context, tenant string) context.Context {
+	return context.WithValue(ctx, ctxKeyTenant{}, tenant)
+}
+
+func TenantFromContext(ctx context.Context) (string, bool) {
+	tenant, ok := ctx.Value(ctxKeyTenant{}).(string)
+	return tenant, ok
+}
+
+type traceIDs struct {
+	traceID, spanID string
+}
+
+// WithTraceIDs 在context中保存trace ID与span ID，输出为trace_id与span_id字段
+func WithTraceIDs(ctx context.Context, traceID, spanID string) context.Context {
+	return context.WithValue(ctx, ctxKeyTrace{}, traceIDs{traceID: traceID, spanID: spanID})
+}
+
+func TraceIDsFromContext(ctx context.Context) (traceID, spanID string, ok bool) {
+	ids, ok := ctx.Value(ctxKeyTrace{}).(traceIDs)
+	return ids.traceID, ids.spanID, ok
+}
+
+func init() {
+	RegisterFieldExtractor(func(ctx context.Context) []zap.Field {
+		if id, ok := RequestIDFromContext(ctx); ok {
+			return []zap.Field{zap.String("request_id", id)}
+		}
+		return nil
+	})
+	RegisterFieldExtractor(func(ctx context.Context) []zap.Field {
+		if tenant, ok := TenantFromContext(ctx); ok {
+			return []zap.Field{zap.String("tenant", tenant)}
+		}
+		return nil
+	})
+	RegisterFieldExtractor(func(ctx context.Context) []zap.Field {
+		if traceID, spanID, ok := TraceIDsFromContext(ctx); ok {
+			return []zap.Field{zap.String("trace_id", traceID), zap.String("span_id", spanID)}
+		}
+		return nil
+	})
+}
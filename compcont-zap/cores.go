@@ -0,0 +1,89 @@
+package compcontzap
+
+import (
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// 组合logger中的一个core，未配置的项沿用base_config与extra_config的结果，例如
+//
+//	cores:
+//	  - { encoding: console, level: debug, output_paths: [stdout] }
+//	  - { encoding: json, level: info, output_paths: ["lumberjack://relative-path/logs/app.log"] }
+//	  - { encoding: json, level: error, output_paths: ["lumberjack://relative-path/logs/error.log"] }
+type CoreConfig struct {
+	Encoding    *string     `ccf:"encoding" json:"encoding" yaml:"encoding"`
+	Level       *string     `ccf:"level" json:"level" yaml:"level"`             // 该core的最低级别，不填则仅受logger级别控制
+	MaxLevel    *string     `ccf:"max_level" json:"max_level" yaml:"max_level"` // 该core的最高级别，不填不限制
+	OutputPaths []string    `ccf:"output_paths" json:"output_paths" yaml:"output_paths"`
+	TimeEncoder TimeEncoder `ccf:"time_encoder" json:"time_encoder" yaml:"time_encoder"`
+
+	Encoder *EncoderConfig `ccf:"encoder" json:"encoder" yaml:"encoder"` // 在logger的encoder配置之上覆盖
+	Buffer  *BufferConfig  `ccf:"buffer" json:"buffer" yaml:"buffer"`    // 不填沿用extra_config中的buffer
+}
+
+// 在logger级别之上，额外按照[min,max]过滤
+type levelRange struct {
+	logger   zapcore.LevelEnabler
+	min, max *zapcore.Level
+}
+
+func (r levelRange) Enabled(lvl zapcore.Level) bool {
+	if r.min != nil && lvl < *r.min {
+		return false
+	}
+	if r.max != nil && lvl > *r.max {
+		return false
+	}
+	return r.logger.Enabled(lvl)
+}
+
+func parseOptionalLevel(text *string) (lvl *zapcore.Level, err error) {
+	if text == nil {
+		return
+	}
+	l, err := zapcore.ParseLevel(*text)
+	if err != nil {
+		return
+	}
+	lvl = &l
+	return
+}
+
+// 按照CoreConfig构造单个core，base为合并后的logger配置
+func (c *CoreConfig) build(base zap.Config, buffer *BufferConfig, enabler zapcore.LevelEnabler, dropped *DropStats) (core zapcore.Core, closeFunc func(), err error) {
+	encoding := base.Encoding
+	if c.Encoding != nil {
+		encoding = *c.Encoding
+	}
+	ec := base.EncoderConfig
+	if err = c.TimeEncoder.mergeTo(&ec); err != nil {
+		return
+	}
+	if c.Encoder != nil {
+		if err = c.Encoder.mergeTo(&ec); err != nil {
+			return
+		}
+	}
+	enc, err := newEncoder(encoding, ec)
+	if err != nil {
+		return
+	}
+
+	r := levelRange{logger: enabler}
+	if r.min, err = parseOptionalLevel(c.Level); err != nil {
+		return
+	}
+	if r.max, err = parseOptionalLevel(c.MaxLevel); err != nil {
+		return
+	}
+
+	paths := c.OutputPaths
+	if len(paths) == 0 {
+		paths = base.OutputPaths
+	}
+	if c.Buffer != nil {
+		buffer = c.Buffer
+	}
+	return newSinkCore(enc, paths, buffer, r, dropped)
+}
@@ -0,0 +1,133 @@
+package compcontzap
+
+import (
+	"fmt"
+	"strings"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// zapcore.EncoderConfig的可配置形式，未配置的项沿用base_config，例如
+//
+//	encoder:
+//	  time_key: ts
+//	  level_encoder: capital
+//	  time_layout: "2006-01-02 15:04:05.000"
+//	  caller_encoder: full
+//	  function_key: func
+//
+// key配置为空字符串表示不输出该项
+type EncoderConfig struct {
+	MessageKey    *string `ccf:"message_key" json:"message_key" yaml:"message_key"`
+	LevelKey      *string `ccf:"level_key" json:"level_key" yaml:"level_key"`
+	TimeKey       *string `ccf:"time_key" json:"time_key" yaml:"time_key"`
+	NameKey       *string `ccf:"name_key" json:"name_key" yaml:"name_key"`
+	CallerKey     *string `ccf:"caller_key" json:"caller_key" yaml:"caller_key"`
+	FunctionKey   *string `ccf:"function_key" json:"function_key" yaml:"function_key"`
+	StacktraceKey *string `ccf:"stacktrace_key" json:"stacktrace_key" yaml:"stacktrace_key"`
+
+	SkipLineEnding   *bool   `ccf:"skip_line_ending" json:"skip_line_ending" yaml:"skip_line_ending"`
+	LineEnding       *string `ccf:"line_ending" json:"line_ending" yaml:"line_ending"`
+	ConsoleSeparator *string `ccf:"console_separator" json:"console_separator" yaml:"console_separator"` // 仅console编码有效
+
+	LevelEncoder    string      `ccf:"level_encoder" json:"level_encoder" yaml:"level_encoder"`          // capital, capital_color, color, lowercase
+	TimeEncoder     TimeEncoder `ccf:"time_encoder" json:"time_encoder" yaml:"time_encoder"`             // 见TimeEncoder常量
+	TimeLayout      string      `ccf:"time_layout" json:"time_layout" yaml:"time_layout"`                // 自定义的time.Format布局，优先于time_encoder
+	DurationEncoder string      `ccf:"duration_encoder" json:"duration_encoder" yaml:"duration_encoder"` // string, nanos, millis, seconds
+	CallerEncoder   string      `ccf:"caller_encoder" json:"caller_encoder" yaml:"caller_encoder"`       // short, full
+	NameEncoder     string      `ccf:"name_encoder" json:"name_encoder" yaml:"name_encoder"`             // full
+}
+
+func (c *EncoderConfig) mergeTo(ec *zapcore.EncoderConfig) (err error) {
+	setString := func(dst *string, src *string) {
+		if src != nil {
+			*dst = *src
+		}
+	}
+	setString(&ec.MessageKey, c.MessageKey)
+	setString(&ec.LevelKey, c.LevelKey)
+	setString(&ec.TimeKey, c.TimeKey)
+	setString(&ec.NameKey, c.NameKey)
+	setString(&ec.CallerKey, c.CallerKey)
+	setString(&ec.FunctionKey, c.FunctionKey)
+	setString(&ec.StacktraceKey, c.StacktraceKey)
+	setString(&ec.LineEnding, c.LineEnding)
+	setString(&ec.ConsoleSeparator, c.ConsoleSeparator)
+	if c.SkipLineEnding != nil {
+		ec.SkipLineEnding = *c.SkipLineEnding
+	}
+
+	switch strings.ToLower(c.LevelEncoder) {
+	case "":
+	case "capital":
+		ec.EncodeLevel = zapcore.CapitalLevelEncoder
+	case "capital_color", "capitalcolor":
+		ec.EncodeLevel = zapcore.CapitalColorLevelEncoder
+	case "color":
+		ec.EncodeLevel = zapcore.LowercaseColorLevelEncoder
+	case "lowercase":
+		ec.EncodeLevel = zapcore.LowercaseLevelEncoder
+	default:
+		return fmt.Errorf("unsupported level encoder: %s", c.LevelEncoder)
+	}
+
+	if c.TimeLayout != "" {
+		ec.EncodeTime = zapcore.TimeEncoderOfLayout(c.TimeLayout)
+	} else if err = c.TimeEncoder.mergeTo(ec); err != nil {
+		return
+	}
+
+	switch strings.ToLower(c.DurationEncoder) {
+	case "":
+	case "string":
+		ec.EncodeDuration = zapcore.StringDurationEncoder
+	case "nanos":
+		ec.EncodeDuration = zapcore.NanosDurationEncoder
+	case "millis", "ms":
+		ec.EncodeDuration = zapcore.MillisDurationEncoder
+	case "seconds":
+		ec.EncodeDuration = zapcore.SecondsDurationEncoder
+	default:
+		return fmt.Errorf("unsupported duration encoder: %s", c.DurationEncoder)
+	}
+
+	switch strings.ToLower(c.CallerEncoder) {
+	case "":
+	case "short":
+		ec.EncodeCaller = zapcore.ShortCallerEncoder
+	case "full":
+		ec.EncodeCaller = zapcore.FullCallerEncoder
+	default:
+		return fmt.Errorf("unsupported caller encoder: %s", c.CallerEncoder)
+	}
+
+	switch strings.ToLower(c.NameEncoder) {
+	case "":
+	case "full":
+		ec.EncodeName = zapcore.FullNameEncoder
+	default:
+		return fmt.Errorf("unsupported name encoder: %s", c.NameEncoder)
+	}
+	return
+}
+
+func (t TimeEncoder) mergeTo(ec *zapcore.EncoderConfig) (err error) {
+	switch t {
+	case "":
+	case TimeEncoderRFC3339:
+		ec.EncodeTime = zapcore.RFC3339NanoTimeEncoder
+	case TimeEncoderRFC3339Sec:
+		ec.EncodeTime = zapcore.RFC3339TimeEncoder
+	case TimeEncoderISO8601:
+		ec.EncodeTime = zapcore.ISO8601TimeEncoder
+	case TimeEncoderEpoch:
+		ec.EncodeTime = zapcore.EpochTimeEncoder
+	case TimeEncoderEpochMillis:
+		ec.EncodeTime = zapcore.EpochMillisTimeEncoder
+	case TimeEncoderEpochNanos:
+		ec.EncodeTime = zapcore.EpochNanosTimeEncoder
+	default:
+		err = fmt.Errorf("unsupported args: %s", t)
+	}
+	return
+}
@@ -0,0 +1,102 @@
+package compcontzap
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+func strPtr(s string) *string {
+	return &s
+}
+
+// 使用production配置与给定的encoder构造logger，返回写入memory sink的一条日志
+func encodeEntry(t *testing.T, name, encoding string, ec EncoderConfig) (entry string, err error) {
+	sink := "memory://test-encoder-" + name
+	l, err := New(Config{BaseConfig: "production", ExtraConfig: ExtraConfig{
+		Encoding:    &encoding,
+		OutputPaths: []string{sink},
+		Encoder:     &ec,
+	}})
+	if err != nil {
+		return
+	}
+	defer Close(l)
+	buf := GetRingBuffer("test-encoder-" + name)
+	buf.Reset()
+	l.Named("a").Named("b").Info("hello", zap.Duration("d", 1500*time.Millisecond))
+	entries := buf.Recent(0)
+	if assert.Len(t, entries, 1) {
+		entry = entries[0]
+	}
+	return
+}
+
+func TestEncoderConfig(t *testing.T) {
+	const (
+		rfc3339 = `\d{4}-\d{2}-\d{2}T\d{2}:\d{2}:\d{2}`
+		zone    = `(Z|[+-]\d{2}:?\d{2})`
+	)
+	cases := []struct {
+		name     string
+		encoding string
+		config   EncoderConfig
+		match    []string // 正则
+	}{
+		{name: "keys", config: EncoderConfig{MessageKey: strPtr("message"), LevelKey: strPtr("severity"), TimeKey: strPtr(""), NameKey: strPtr("name"), CallerKey: strPtr(""), FunctionKey: strPtr("func")},
+			match: []string{`^\{"severity":"info","name":"a\.b","func":"[^"]*encodeEntry","message":"hello","d":1\.5\}\n$`}},
+		{name: "level-capital", config: EncoderConfig{LevelEncoder: "capital"}, match: []string{`"level":"INFO"`}},
+		{name: "level-capital-color", config: EncoderConfig{LevelEncoder: "capital_color"}, match: []string{`"level":"\\u001b\[34mINFO\\u001b\[0m"`}},
+		{name: "level-color", config: EncoderConfig{LevelEncoder: "color"}, match: []string{`"level":"\\u001b\[34minfo\\u001b\[0m"`}},
+		{name: "level-lowercase", config: EncoderConfig{LevelEncoder: "lowercase"}, match: []string{`"level":"info"`}},
+		{name: "time-rfc3339nano", config: EncoderConfig{TimeEncoder: TimeEncoderRFC3339}, match: []string{`"ts":"` + rfc3339 + `(\.\d+)?` + zone + `"`}},
+		{name: "time-rfc3339", config: EncoderConfig{TimeEncoder: TimeEncoderRFC3339Sec}, match: []string{`"ts":"` + rfc3339 + zone + `"`}},
+		{name: "time-iso8601", config: EncoderConfig{TimeEncoder: TimeEncoderISO8601}, match: []string{`"ts":"` + rfc3339 + `\.\d{3}` + zone + `"`}},
+		{name: "time-epoch", config: EncoderConfig{TimeEncoder: TimeEncoderEpoch}, match: []string{`"ts":\d{10}\.\d+,`}},
+		{name: "time-epoch-millis", config: EncoderConfig{TimeEncoder: TimeEncoderEpochMillis}, match: []string{`"ts":\d{13}(\.\d+)?,`}},
+		{name: "time-epoch-nanos", config: EncoderConfig{TimeEncoder: TimeEncoderEpochNanos}, match: []string{`"ts":\d{19},`}},
+		{name: "time-layout", config: EncoderConfig{TimeEncoder: TimeEncoderEpoch, TimeLayout: "2006/01/02"}, match: []string{`"ts":"\d{4}/\d{2}/\d{2}"`}},
+		{name: "duration-string", config: EncoderConfig{DurationEncoder: "string"}, match: []string{`"d":"1\.5s"`}},
+		{name: "duration-nanos", config: EncoderConfig{DurationEncoder: "nanos"}, match: []string{`"d":1500000000\}`}},
+		{name: "duration-millis", config: EncoderConfig{DurationEncoder: "millis"}, match: []string{`"d":1500\}`}},
+		{name: "duration-seconds", config: EncoderConfig{DurationEncoder: "seconds"}, match: []string{`"d":1\.5\}`}},
+		{name: "caller-short", config: EncoderConfig{CallerEncoder: "short"}, match: []string{`"caller":"compcont-zap/encoder_test\.go:\d+"`}},
+		{name: "caller-full", config: EncoderConfig{CallerEncoder: "full"}, match: []string{`"caller":"/\S+/compcont-zap/encoder_test\.go:\d+"`}},
+		{name: "name-full", config: EncoderConfig{NameEncoder: "full"}, match: []string{`"logger":"a\.b"`}},
+		{name: "line-ending", config: EncoderConfig{LineEnding: strPtr("\r\n")}, match: []string{`\}\r\n$`}},
+		{name: "skip-line-ending", config: EncoderConfig{SkipLineEnding: func() *bool { b := true; return &b }()}, match: []string{`\}$`}},
+		{name: "console", encoding: "console", config: EncoderConfig{ConsoleSeparator: strPtr(" | "), TimeKey: strPtr(""), CallerKey: strPtr(""), LevelEncoder: "capital"},
+			match: []string{`^INFO \| a\.b \| hello \| \{"d": 1\.5\}\n$`}},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			encoding := c.encoding
+			if encoding == "" {
+				encoding = "json"
+			}
+			entry, err := encodeEntry(t, c.name, encoding, c.config)
+			if !assert.NoError(t, err) {
+				return
+			}
+			for _, re := range c.match {
+				assert.Regexp(t, re, entry)
+			}
+		})
+	}
+}
+
+func TestEncoderConfigInvalid(t *testing.T) {
+	for i, ec := range []EncoderConfig{
+		{LevelEncoder: "unknown"},
+		{TimeEncoder: "unknown"},
+		{DurationEncoder: "unknown"},
+		{CallerEncoder: "unknown"},
+		{NameEncoder: "unknown"},
+	} {
+		_, err := encodeEntry(t, fmt.Sprint("invalid-", i), "json", ec)
+		assert.Error(t, err, "%+v", ec)
+	}
+}
@@ -0,0 +1,312 @@
+package compcontzap
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// 按logger名称覆盖的级别快照，变更时整体替换
+type namedLevels struct {
+	levels map[string]zapcore.Level
+	min    zapcore.Level // 所有覆盖级别中的最低级别，用于快速判断
+}
+
+// 一次临时的级别调整
+type LevelBump struct {
+	Name      string        `json:"name"`
+	Level     zapcore.Level `json:"level"`
+	ExpiresAt time.Time     `json:"expires_at"`
+
+	previous *zapcore.Level // 根级别临时调整前的级别
+	timer    *time.Timer
+}
+
+// 控制logger的根级别以及按名称(logger.Named)覆盖的级别，
+// 名称按照"."分段前缀匹配，"a"同时作用于"a"与"a.b"，最长匹配优先
+type LevelController struct {
+	level    zap.AtomicLevel
+	snapshot atomic.Pointer[namedLevels]
+
+	mu    sync.Mutex
+	named map[string]zapcore.Level // 配置或运行时设置的覆盖级别
+	bumps map[string]*LevelBump    // 临时调整，到期后自动恢复
+}
+
+func newLevelController(level zapcore.Level) *LevelController {
+	c := &LevelController{
+		level: zap.NewAtomicLevelAt(level),
+		named: map[string]zapcore.Level{},
+		bumps: map[string]*LevelBump{},
+	}
+	c.snapshot.Store(&namedLevels{})
+	return c
+}
+
+func parseNamedLevels(named map[string]string) (levels map[string]zapcore.Level, err error) {
+	levels = make(map[string]zapcore.Level, len(named))
+	for name, text := range named {
+		var lvl zapcore.Level
+		if lvl, err = zapcore.ParseLevel(text); err != nil {
+			err = fmt.Errorf("named level %s: %w", name, err)
+			return
+		}
+		levels[name] = lvl
+	}
+	return
+}
+
+// 必须持有mu
+func (c *LevelController) rebuildSnapshot() {
+	levels := maps.Clone(c.named)
+	if levels == nil {
+		levels = map[string]zapcore.Level{}
+	}
+	for name, bump := range c.bumps {
+		if name != "" {
+			levels[name] = bump.Level
+		}
+	}
+	s := &namedLevels{levels: levels, min: zapcore.InvalidLevel}
+	for _, lvl := range levels {
+		if s.min == zapcore.InvalidLevel || lvl < s.min {
+			s.min = lvl
+		}
+	}
+	c.snapshot.Store(s)
+}
+
+// 至少有一个logger会输出该级别
+func (c *LevelController) Enabled(lvl zapcore.Level) bool {
+	if c.level.Enabled(lvl) {
+		return true
+	}
+	s := c.snapshot.Load()
+	return len(s.levels) > 0 && lvl >= s.min
+}
+
+// 所有logger中最低的生效级别
+func (c *LevelController) Level() zapcore.Level {
+	lvl := c.level.Level()
+	if s := c.snapshot.Load(); len(s.levels) > 0 && s.min < lvl {
+		lvl = s.min
+	}
+	return lvl
+}
+
+// 名称为name的logger生效的级别
+func (c *LevelController) LevelOf(name string) zapcore.Level {
+	s := c.snapshot.Load()
+	for len(s.levels) > 0 && name != "" {
+		if lvl, ok := s.levels[name]; ok {
+			return lvl
+		}
+		i := strings.LastIndexByte(name, '.')
+		if i < 0 {
+			break
+		}
+		name = name[:i]
+	}
+	return c.level.Level()
+}
+
+func (c *LevelController) EnabledFor(name string, lvl zapcore.Level) bool {
+	return lvl >= c.LevelOf(name)
+}
+
+// 根级别，可直接修改或通过其ServeHTTP暴露
+func (c *LevelController) AtomicLevel() zap.AtomicLevel {
+	return c.level
+}
+
+// SetLevel 永久修改根级别，根级别有临时调整时同时作为其到期后恢复的级别
+func (c *LevelController) SetLevel(lvl zapcore.Level) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if bump, ok := c.bumps[""]; ok {
+		bump.previous = &lvl
+	}
+	c.level.SetLevel(lvl)
+}
+
+// 重新加载配置中的根级别，根级别有临时调整时只修改其到期后恢复的级别
+func (c *LevelController) setBaseLevel(lvl zapcore.Level) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if bump, ok := c.bumps[""]; ok {
+		bump.previous = &lvl
+		return
+	}
+	c.level.SetLevel(lvl)
+}
+
+func (c *LevelController) NamedLevels() map[string]zapcore.Level {
+	return maps.Clone(c.snapshot.Load().levels)
+}
+
+func (c *LevelController) SetNamedLevel(name string, lvl zapcore.Level) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.named[name] = lvl
+	c.rebuildSnapshot()
+}
+
+func (c *LevelController) RemoveNamedLevel(name string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.named, name)
+	c.rebuildSnapshot()
+}
+
+// 重新加载配置中的覆盖级别，临时调整不受影响
+func (c *LevelController) setNamedLevels(levels map[string]zapcore.Level) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.named = levels
+	c.rebuildSnapshot()
+}
+
+// 测试中替换以手动触发到期
+var afterFunc = time.AfterFunc
+
+// Bump 临时调整名称为name的logger的级别，ttl到期后自动恢复，name为空表示根级别
+func (c *LevelController) Bump(name string, lvl zapcore.Level, ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	bump := &LevelBump{Name: name, Level: lvl, ExpiresAt: time.Now().Add(ttl)}
+	if old, ok := c.bumps[name]; ok {
+		old.timer.Stop()
+		bump.previous = old.previous
+	} else if name == "" {
+		previous := c.level.Level()
+		bump.previous = &previous
+	}
+	bump.timer = afterFunc(ttl, func() {
+		c.revert(bump)
+	})
+	c.bumps[name] = bump
+	if name == "" {
+		c.level.SetLevel(lvl)
+	}
+	c.rebuildSnapshot()
+}
+
+func (c *LevelController) revert(bump *LevelBump) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.bumps[bump.Name] != bump {
+		return
+	}
+	delete(c.bumps, bump.Name)
+	// 根级别在调整期间被直接修改过(例如AtomicLevel().SetLevel)时保留修改后的级别
+	if bump.previous != nil && c.level.Level() == bump.Level {
+		c.level.SetLevel(*bump.previous)
+	}
+	c.rebuildSnapshot()
+}
+
+// 当前所有的临时调整
+func (c *LevelController) Bumps() (bumps []LevelBump) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, b := range c.bumps {
+		bumps = append(bumps, LevelBump{Name: b.Name, Level: b.Level, ExpiresAt: b.ExpiresAt})
+	}
+	return
+}
+
+func (c *LevelController) stop() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for _, b := range c.bumps {
+		b.timer.Stop()
+	}
+}
+
+type levelStatus struct {
+	Level       zapcore.Level            `json:"level"`
+	NamedLevels map[string]zapcore.Level `json:"named_levels,omitempty"`
+	Bumps       []LevelBump              `json:"bumps,omitempty"`
+}
+
+type levelRequest struct {
+	Name  string         `json:"name"`
+	Level *zapcore.Level `json:"level"`
+	TTL   string         `json:"ttl"` // 为空表示永久修改
+}
+
+// ServeHTTP 级别管理接口
+//
+//	GET    返回根级别、按名称覆盖的级别与临时调整
+//	PUT    {"name":"db","level":"debug","ttl":"10m"}，name为空修改根级别，ttl为空永久修改
+//	DELETE ?name=db 移除名称为db的覆盖级别
+func (c *LevelController) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	writeJSON := func(status int, v any) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		_ = json.NewEncoder(w).Encode(v)
+	}
+	writeError := func(status int, err error) {
+		writeJSON(status, map[string]string{"error": err.Error()})
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodPut:
+		var req levelRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			writeError(http.StatusBadRequest, err)
+			return
+		}
+		if req.Level == nil {
+			writeError(http.StatusBadRequest, fmt.Errorf("level is required"))
+			return
+		}
+		switch {
+		case req.TTL != "":
+			ttl, err := time.ParseDuration(req.TTL)
+			if err != nil || ttl <= 0 {
+				writeError(http.StatusBadRequest, fmt.Errorf("invalid ttl: %s", req.TTL))
+				return
+			}
+			c.Bump(req.Name, *req.Level, ttl)
+		case req.Name == "":
+			c.SetLevel(*req.Level)
+		default:
+			c.SetNamedLevel(req.Name, *req.Level)
+		}
+	case http.MethodDelete:
+		name := r.URL.Query().Get("name")
+		if name == "" {
+			writeError(http.StatusBadRequest, fmt.Errorf("name is required"))
+			return
+		}
+		c.RemoveNamedLevel(name)
+	default:
+		writeError(http.StatusMethodNotAllowed, fmt.Errorf("method %s not allowed", r.Method))
+		return
+	}
+	writeJSON(http.StatusOK, levelStatus{
+		Level:       c.level.Level(),
+		NamedLevels: c.NamedLevels(),
+		Bumps:       c.Bumps(),
+	})
+}
+
+// Levels 获取由New创建的logger的级别控制器
+func Levels(l *zap.Logger) (*LevelController, error) {
+	s, err := stateOf(l)
+	if err != nil {
+		return nil, err
+	}
+	return s.levels, nil
+}
@@ -0,0 +1,96 @@
+package compcontzap
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap/zapcore"
+)
+
+func TestLevelController(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "out.log")
+	level, encoding := "info", "json"
+	logger, err := New(Config{
+		BaseConfig: "production",
+		ExtraConfig: ExtraConfig{
+			Level:       &level,
+			Encoding:    &encoding,
+			OutputPaths: []string{file},
+			NamedLevels: map[string]string{"db": "debug"},
+		},
+	})
+	assert.NoError(t, err)
+	defer Close(logger)
+
+	levels, err := Levels(logger)
+	assert.NoError(t, err)
+
+	logger.Named("db").Named("sql").Debug("db debug")
+	logger.Named("web").Debug("web debug hidden")
+	expire := manualExpiry(t)
+	levels.Bump("web", zapcore.DebugLevel, time.Minute)
+	logger.Named("web").Debug("web debug bumped")
+	expire()
+	logger.Named("web").Debug("web debug reverted")
+
+	rec := httptest.NewRecorder()
+	levels.ServeHTTP(rec, httptest.NewRequest(http.MethodPut, "/", strings.NewReader(`{"level":"debug"}`)))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	logger.Named("web").Debug("root debug")
+	assert.Equal(t, zapcore.DebugLevel, levels.AtomicLevel().Level())
+
+	assert.NoError(t, logger.Sync())
+	out, err := os.ReadFile(file)
+	assert.NoError(t, err)
+	assert.Contains(t, string(out), "db debug")
+	assert.NotContains(t, string(out), "web debug hidden")
+	assert.Contains(t, string(out), "web debug bumped")
+	assert.NotContains(t, string(out), "web debug reverted")
+	assert.Contains(t, string(out), "root debug")
+}
+
+// 替换afterFunc，返回的函数触发所有已登记的到期回调
+func manualExpiry(t *testing.T) (expire func()) {
+	var pending []func()
+	afterFunc = func(d time.Duration, f func()) *time.Timer {
+		pending = append(pending, f)
+		return time.NewTimer(time.Hour)
+	}
+	t.Cleanup(func() { afterFunc = time.AfterFunc })
+	return func() {
+		fns := pending
+		pending = nil
+		for _, f := range fns {
+			f()
+		}
+	}
+}
+
+func TestLevelBumpKeepsPermanentChange(t *testing.T) {
+	expire := manualExpiry(t)
+
+	c := newLevelController(zapcore.InfoLevel)
+	c.Bump("", zapcore.DebugLevel, time.Minute)
+	assert.Equal(t, zapcore.DebugLevel, c.AtomicLevel().Level())
+	c.SetLevel(zapcore.WarnLevel) // 调整期间永久修改
+	expire()
+	assert.Equal(t, zapcore.WarnLevel, c.AtomicLevel().Level())
+
+	c.Bump("", zapcore.DebugLevel, time.Minute)
+	c.AtomicLevel().SetLevel(zapcore.ErrorLevel) // 绕过controller直接修改
+	expire()
+	assert.Equal(t, zapcore.ErrorLevel, c.AtomicLevel().Level())
+
+	c.Bump("", zapcore.DebugLevel, time.Minute)
+	c.setBaseLevel(zapcore.InfoLevel) // 重新加载配置不打断临时调整
+	assert.Equal(t, zapcore.DebugLevel, c.AtomicLevel().Level())
+	expire()
+	assert.Equal(t, zapcore.InfoLevel, c.AtomicLevel().Level())
+	c.stop()
+}
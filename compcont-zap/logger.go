@@ -1,14 +1,20 @@
 package compcontzap
 
 import (
+	"cmp"
 	"context"
 	"net/http"
+	"slices"
+	"sync"
+	"sync/atomic"
 
 	"go.uber.org/zap"
 )
 
 var (
-	defaultLogger *zap.Logger
+	defaultLogger atomic.Pointer[zap.Logger]
+	fallbackOnce  sync.Once
+	fallback      *zap.Logger
 )
 
 type ctxKeyLogger struct{}
@@ -21,21 +27,154 @@ func WithContext(ctx context.Context, logger *zap.Logger) context.Context {
 	return context.WithValue(ctx, ctxKeyLogger{}, logger)
 }
 
+// FromContext 获取context中的logger(未保存时为默认logger)，并附加ContextFields中的字段，
+// 返回的logger已经带有这些字段，不应再通过WithContext保存到同一个context中
 func FromContext(ctx context.Context) *zap.Logger {
 	val, ok := ctx.Value(ctxKeyLogger{}).(*zap.Logger)
 	if !ok {
-		return GetDefault()
+		val = GetDefault()
+	}
+	if fields := ContextFields(ctx); len(fields) > 0 {
+		return val.With(fields...)
 	}
 	return val
 }
 
+// SetDefault 直接设置默认logger，l为nil表示恢复为未设置的状态，之后ClaimDefault的变化会覆盖此设置
 func SetDefault(l *zap.Logger) {
-	defaultLogger = l
+	if defaultLogger.Swap(l) != l {
+		notifyDefault(l)
+	}
 }
 
+// LookupDefault 获取通过SetDefault或ClaimDefault设置的默认logger，未设置时ok为false，
+// 用于保存并在之后通过SetDefault恢复默认logger(包括未设置的状态)
+func LookupDefault() (l *zap.Logger, ok bool) {
+	l = defaultLogger.Load()
+	return l, l != nil
+}
+
+// GetDefault 获取默认logger，未设置时使用development配置的logger
 func GetDefault() *zap.Logger {
-	if defaultLogger == nil {
-		defaultLogger, _ = zap.NewDevelopmentConfig().Build()
+	if l := defaultLogger.Load(); l != nil {
+		return l
+	}
+	fallbackOnce.Do(func() {
+		fallback, _ = zap.NewDevelopmentConfig().Build()
+	})
+	return fallback
+}
+
+var (
+	listenersMu    sync.Mutex
+	listeners      = map[uint64]func(l *zap.Logger){}
+	nextListenerID uint64
+)
+
+// OnDefaultChange 注册默认logger变化时的回调，l为nil表示恢复为development配置的logger，返回取消注册的函数
+func OnDefaultChange(fn func(l *zap.Logger)) (remove func()) {
+	listenersMu.Lock()
+	defer listenersMu.Unlock()
+	id := nextListenerID
+	nextListenerID++
+	listeners[id] = fn
+	return func() {
+		listenersMu.Lock()
+		defer listenersMu.Unlock()
+		delete(listeners, id)
+	}
+}
+
+func notifyDefault(l *zap.Logger) {
+	listenersMu.Lock()
+	fns := make([]func(l *zap.Logger), 0, len(listeners))
+	for _, fn := range listeners {
+		fns = append(fns, fn)
+	}
+	listenersMu.Unlock()
+	for _, fn := range fns {
+		fn(l)
+	}
+}
+
+// 申请成为默认logger的参数
+type DefaultClaim struct {
+	Priority       int    // 优先级高者成为默认logger
+	Key            string // 优先级相同时Key字典序小者胜出，组件中为组件的绝对路径
+	ReplaceGlobals bool   // 成为默认logger时同时调用zap.ReplaceGlobals
+}
+
+type defaultClaim struct {
+	DefaultClaim
+	logger *zap.Logger
+}
+
+var (
+	claimsMu      sync.Mutex
+	claims        []*defaultClaim
+	winner        *defaultClaim
+	restoreGlobal func()
+)
+
+// ClaimDefault 申请将l作为默认logger，存在多个申请时按照优先级、Key的顺序确定唯一的默认logger，
+// 与构造顺序无关。返回撤销申请的函数，撤销后由剩余的申请中重新选择
+func ClaimDefault(l *zap.Logger, claim DefaultClaim) (release func()) {
+	c := &defaultClaim{DefaultClaim: claim, logger: l}
+	claimsMu.Lock()
+	claims = append(claims, c)
+	next, changed := electDefault()
+	claimsMu.Unlock()
+	if changed {
+		notifyDefault(next)
+	}
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			claimsMu.Lock()
+			claims = slices.DeleteFunc(claims, func(item *defaultClaim) bool { return item == c })
+			next, changed := electDefault()
+			claimsMu.Unlock()
+			if changed {
+				notifyDefault(next)
+			}
+		})
+	}
+}
+
+// 重新选择默认logger，必须持有claimsMu，默认logger变化时返回新的logger
+func electDefault() (l *zap.Logger, changed bool) {
+	var next *defaultClaim
+	if len(claims) > 0 {
+		next = slices.MinFunc(claims, func(a, b *defaultClaim) int {
+			if a.Priority != b.Priority {
+				return cmp.Compare(b.Priority, a.Priority)
+			}
+			return cmp.Compare(a.Key, b.Key)
+		})
+	}
+	if next == winner {
+		return
+	}
+	previous := winner
+	winner = next
+
+	if next != nil && next.ReplaceGlobals {
+		undo := zap.ReplaceGlobals(next.logger)
+		if restoreGlobal == nil {
+			restoreGlobal = undo
+		}
+	} else if restoreGlobal != nil {
+		restoreGlobal()
+		restoreGlobal = nil
+	}
+
+	if next != nil {
+		l = next.logger
+	} else if previous == nil || defaultLogger.Load() != previous.logger {
+		// 默认logger已经被SetDefault替换，保持不变
+		return
 	}
-	return defaultLogger
+	changed = defaultLogger.Swap(l) != l
+	return
 }
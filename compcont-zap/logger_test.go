@@ -0,0 +1,52 @@
+package compcontzap
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+func TestClaimDefault(t *testing.T) {
+	a, b, c := zap.NewNop(), zap.NewNop(), zap.NewNop()
+	var changes []*zap.Logger
+	remove := OnDefaultChange(func(l *zap.Logger) { changes = append(changes, l) })
+	defer remove()
+
+	releaseB := ClaimDefault(b, DefaultClaim{Key: "/b"})
+	releaseA := ClaimDefault(a, DefaultClaim{Key: "/a"})
+	assert.Same(t, a, GetDefault())
+	releaseC := ClaimDefault(c, DefaultClaim{Key: "/c", Priority: 1, ReplaceGlobals: true})
+	assert.Same(t, c, GetDefault())
+	assert.Same(t, c, zap.L())
+
+	releaseC()
+	assert.Same(t, a, GetDefault())
+	assert.NotSame(t, c, zap.L())
+	releaseA()
+	releaseB()
+	assert.NotNil(t, GetDefault())
+	assert.Equal(t, []*zap.Logger{b, a, c, a, b, nil}, changes)
+}
+
+func TestFromContextFields(t *testing.T) {
+	encoding := "json"
+	logger, err := New(Config{
+		BaseConfig:  "production",
+		ExtraConfig: ExtraConfig{Encoding: &encoding, OutputPaths: []string{"memory://test-context"}},
+	})
+	assert.NoError(t, err)
+	defer Close(logger)
+
+	ctx := WithContext(context.Background(), logger)
+	ctx = WithTenant(ctx, "acme")
+	ctx = AddFields(ctx, zap.String("job", "sync"))
+	FromContext(AddFields(ctx, zap.Int("attempt", 2))).Info("deep")
+	FromContext(ctx).Info("shallow")
+
+	recent := GetRingBuffer("test-context").Recent(0)
+	assert.Len(t, recent, 2)
+	assert.Contains(t, recent[0], `"tenant":"acme","job":"sync","attempt":2`)
+	assert.Contains(t, recent[1], `"tenant":"acme","job":"sync"}`)
+}
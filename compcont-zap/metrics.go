@@ -0,0 +1,72 @@
+package compcontzap
+
+import (
+	"github.com/go-compcont/compcont-std/metrics"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// NewMetricsCore 按级别统计日志条数的core，输出为compcont_log_entries_total{logger,level}，
+// name为logger标签的值，通常为组件路径；统计的是通过级别控制与采样的日志
+func NewMetricsCore(r *metrics.Registry, name string) zapcore.Core {
+	core := &metricsCore{}
+	for lvl := zapcore.DebugLevel; lvl <= zapcore.FatalLevel; lvl++ {
+		core.counters[lvl-zapcore.DebugLevel] = r.Counter("compcont_log_entries_total", "Number of log entries written per level.",
+			metrics.Labels{"logger": name, "level": lvl.String()})
+	}
+	return core
+}
+
+type metricsCore struct {
+	counters [zapcore.FatalLevel - zapcore.DebugLevel + 1]*metrics.Counter
+}
+
+func (c *metricsCore) Enabled(lvl zapcore.Level) bool {
+	return lvl >= zapcore.DebugLevel && lvl <= zapcore.FatalLevel
+}
+
+func (c *metricsCore) With(fields []zapcore.Field) zapcore.Core {
+	return c
+}
+
+func (c *metricsCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+func (c *metricsCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	c.counters[ent.Level-zapcore.DebugLevel].Inc()
+	return nil
+}
+
+func (c *metricsCore) Sync() error {
+	return nil
+}
+
+// 以compcont_log_dropped_total{logger,reason}输出logger被采样、限流与缓冲区满丢弃的条数，返回移除指标的函数
+func registerDropMetrics(r *metrics.Registry, name string, l *zap.Logger) (unregister func(), err error) {
+	stats, err := Dropped(l)
+	if err != nil {
+		return
+	}
+	const help = "Number of log entries dropped by sampling, rate limiting or buffer overflow."
+	unregisters := []func(){
+		r.CounterFunc("compcont_log_dropped_total", help, metrics.Labels{"logger": name, "reason": "sampled"}, func() float64 {
+			return float64(stats.Sampled())
+		}),
+		r.CounterFunc("compcont_log_dropped_total", help, metrics.Labels{"logger": name, "reason": "rate_limited"}, func() float64 {
+			return float64(stats.RateLimited())
+		}),
+		r.CounterFunc("compcont_log_dropped_total", help, metrics.Labels{"logger": name, "reason": "overflowed"}, func() float64 {
+			return float64(stats.Overflowed())
+		}),
+	}
+	unregister = func() {
+		for _, fn := range unregisters {
+			fn()
+		}
+	}
+	return
+}
@@ -0,0 +1,38 @@
+package compcontzap
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/go-compcont/compcont-std/metrics"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMetricsCore(t *testing.T) {
+	r := metrics.NewRegistry()
+	level := "info"
+	l, err := NewWithCores(Config{BaseConfig: "production", ExtraConfig: ExtraConfig{Level: &level, OutputPaths: []string{"memory://test-metrics"}}}, NewMetricsCore(r, "/logger"))
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer Close(l)
+	unregister, err := registerDropMetrics(r, "/logger", l)
+	assert.NoError(t, err)
+
+	l.Debug("filtered")
+	l.Info("a")
+	l.Info("b")
+	l.Warn("c")
+
+	var b strings.Builder
+	assert.NoError(t, r.WriteText(&b))
+	assert.Contains(t, b.String(), `compcont_log_entries_total{level="debug",logger="/logger"} 0`)
+	assert.Contains(t, b.String(), `compcont_log_entries_total{level="info",logger="/logger"} 2`)
+	assert.Contains(t, b.String(), `compcont_log_entries_total{level="warn",logger="/logger"} 1`)
+	assert.Contains(t, b.String(), `compcont_log_dropped_total{logger="/logger",reason="sampled"} 0`)
+
+	unregister()
+	b.Reset()
+	assert.NoError(t, r.WriteText(&b))
+	assert.NotContains(t, b.String(), "compcont_log_dropped_total")
+}
@@ -0,0 +1,171 @@
+package compcontzap
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+const DefaultRequestIDHeader = "X-Request-ID"
+
+type MiddlewareOptions struct {
+	RequestIDHeader   string        // 读取与回写请求ID的header，默认X-Request-ID
+	GenerateRequestID func() string // header中没有合法的请求ID时生成，默认16字节随机hex
+	DisableAccessLog  bool          // 不输出访问日志
+	AccessLogMessage  string        // 访问日志的消息，默认"http access"
+}
+
+type ctxKeyRequestID struct{}
+
+// RequestIDFromContext 获取Middleware设置的请求ID
+func RequestIDFromContext(ctx context.Context) (string, bool) {
+	id, ok := ctx.Value(ctxKeyRequestID{}).(string)
+	return id, ok
+}
+
+// 客户端传入的请求ID会写入日志与响应header，只接受不超过128个字符的[A-Za-z0-9._-]
+func validRequestID(id string) bool {
+	if id == "" || len(id) > 128 {
+		return false
+	}
+	for _, c := range []byte(id) {
+		switch {
+		case 'a' <= c && c <= 'z', 'A' <= c && c <= 'Z', '0' <= c && c <= '9', c == '.', c == '_', c == '-':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+func generateRequestID() string {
+	var b [16]byte
+	_, _ = rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
+
+// 解析W3C traceparent: version-traceid-spanid-flags
+func parseTraceparent(header string) (traceID, spanID string, ok bool) {
+	parts := strings.Split(strings.TrimSpace(header), "-")
+	if len(parts) != 4 || len(parts[1]) != 32 || len(parts[2]) != 16 {
+		return
+	}
+	return parts[1], parts[2], true
+}
+
+// 记录响应的状态码与大小
+type responseRecorder struct {
+	http.ResponseWriter
+	status int
+	size   int64
+}
+
+func (r *responseRecorder) WriteHeader(status int) {
+	if r.status == 0 {
+		r.status = status
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *responseRecorder) Write(p []byte) (n int, err error) {
+	if r.status == 0 {
+		r.status = http.StatusOK
+	}
+	n, err = r.ResponseWriter.Write(p)
+	r.size += int64(n)
+	return
+}
+
+// 直接断言http.Flusher的handler(如SSE)依赖该方法，未写入时按200记录
+func (r *responseRecorder) Flush() {
+	if r.status == 0 {
+		r.status = http.StatusOK
+	}
+	_ = http.NewResponseController(r.ResponseWriter).Flush()
+}
+
+// 供http.ResponseController访问Hijack等能力
+func (r *responseRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
+
+// Middleware 为每个请求派生携带method、path、remote的logger并保存到context，
+// 请求ID与trace ID保存在context中，handler中使用FromContext(r.Context())获取的logger会同时带上这些字段，
+// 请求结束时输出访问日志。l为空时以请求context中的logger(默认logger)为基础
+func Middleware(l *zap.Logger, opts *MiddlewareOptions) func(http.Handler) http.Handler {
+	var o MiddlewareOptions
+	if opts != nil {
+		o = *opts
+	}
+	if o.RequestIDHeader == "" {
+		o.RequestIDHeader = DefaultRequestIDHeader
+	}
+	if o.GenerateRequestID == nil {
+		o.GenerateRequestID = generateRequestID
+	}
+	if o.AccessLogMessage == "" {
+		o.AccessLogMessage = "http access"
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+
+			requestID := r.Header.Get(o.RequestIDHeader)
+			if !validRequestID(requestID) {
+				requestID = o.GenerateRequestID()
+			}
+			w.Header().Set(o.RequestIDHeader, requestID)
+
+			// 请求ID与trace ID保存在context中，由FromContext的提取器输出
+			ctx := context.WithValue(r.Context(), ctxKeyRequestID{}, requestID)
+			if _, _, ok := TraceIDsFromContext(ctx); !ok {
+				if traceID, spanID, ok := parseTraceparent(r.Header.Get("traceparent")); ok {
+					ctx = WithTraceIDs(ctx, traceID, spanID)
+				}
+			}
+			logger := l
+			if logger == nil {
+				logger, _ = r.Context().Value(ctxKeyLogger{}).(*zap.Logger)
+			}
+			if logger == nil {
+				logger = GetDefault()
+			}
+			logger = logger.With(
+				zap.String("method", r.Method),
+				zap.String("path", r.URL.Path),
+				zap.String("remote", r.RemoteAddr),
+			)
+			// 不修改调用方的请求，访问日志使用派生的请求
+			r = r.WithContext(WithContext(ctx, logger))
+
+			rec := &responseRecorder{ResponseWriter: w}
+			next.ServeHTTP(rec, r)
+			if o.DisableAccessLog {
+				return
+			}
+
+			status := rec.status
+			if status == 0 {
+				status = http.StatusOK
+			}
+			level := zapcore.InfoLevel
+			if status >= http.StatusInternalServerError {
+				level = zapcore.ErrorLevel
+			}
+			FromContext(r.Context()).WithOptions(zap.WithCaller(false), zap.AddStacktrace(zapcore.FatalLevel)).Log(
+				level,
+				o.AccessLogMessage,
+				zap.Int("status", status),
+				zap.Int64("size", rec.size),
+				zap.Duration("latency", time.Since(start)),
+			)
+		})
+	}
+}
@@ -0,0 +1,82 @@
+package compcontzap
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+func TestMiddleware(t *testing.T) {
+	encoding := "json"
+	logger, err := New(Config{
+		BaseConfig:  "production",
+		ExtraConfig: ExtraConfig{Encoding: &encoding, OutputPaths: []string{"memory://test-middleware"}},
+	})
+	assert.NoError(t, err)
+	defer Close(logger)
+
+	handler := Middleware(logger, nil)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		FromContext(r.Context()).Info("handling")
+		w.WriteHeader(http.StatusCreated)
+		_, _ = w.Write([]byte("hello"))
+	}))
+
+	req := httptest.NewRequest(http.MethodPost, "/items", nil)
+	req.Header.Set("X-Request-ID", "req-1")
+	req.Header.Set("traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	assert.Equal(t, "req-1", rec.Header().Get("X-Request-ID"))
+	_, ok := RequestIDFromContext(req.Context())
+	assert.False(t, ok, "caller's request must not be modified")
+
+	recent := GetRingBuffer("test-middleware").Recent(0)
+	assert.Len(t, recent, 2)
+	assert.Contains(t, recent[0], `"method":"POST","path":"/items"`)
+	assert.Contains(t, recent[0], `"request_id":"req-1"`)
+	assert.Contains(t, recent[0], `"trace_id":"4bf92f3577b34da6a3ce929d0e0e4736"`)
+	assert.Contains(t, recent[1], `"msg":"http access"`)
+	assert.Contains(t, recent[1], `"status":201,"size":5`)
+}
+
+func TestMiddlewareInvalidRequestID(t *testing.T) {
+	handler := Middleware(zap.NewNop(), &MiddlewareOptions{
+		GenerateRequestID: func() string { return "generated" },
+	})(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+
+	for id, want := range map[string]string{
+		"":                       "generated",
+		"abc.DEF_123-x":          "abc.DEF_123-x",
+		"bad id":                 "generated",
+		"bad\"quote":             "generated",
+		"forged\nline":           "generated",
+		strings.Repeat("a", 128): strings.Repeat("a", 128),
+		strings.Repeat("a", 129): "generated",
+	} {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("X-Request-ID", id)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		assert.Equal(t, want, rec.Header().Get("X-Request-ID"), id)
+	}
+}
+
+func TestMiddlewareFlush(t *testing.T) {
+	handler := Middleware(zap.NewNop(), nil)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		flusher, ok := w.(http.Flusher)
+		if !assert.True(t, ok, "response writer must implement http.Flusher") {
+			return
+		}
+		_, _ = w.Write([]byte("event"))
+		flusher.Flush()
+	}))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/events", nil))
+	assert.True(t, rec.Flushed)
+	assert.Equal(t, "event", rec.Body.String())
+}
@@ -0,0 +1,408 @@
+package compcontzap
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"path"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// 敏感信息脱敏，例如
+//
+//	redact:
+//	  use_defaults: true
+//	  fields: ["*_key", "cookie"]
+//	  values: ['\d{17}[\dXx]']
+//
+// 结构体字段标记`redact:"true"`时，通过zap.Any输出会被脱敏；
+// 通过zap.Object输出时按MarshalLogObject写入的键匹配顶层结构体字段的json名或字段名。
+// 实现了json.Marshaler或encoding.TextMarshaler的值先序列化，再按字段名与值的正则脱敏
+type RedactConfig struct {
+	UseDefaults bool     `ccf:"use_defaults" json:"use_defaults" yaml:"use_defaults"` // 启用DefaultRedactFields与DefaultRedactValues
+	Fields      []string `ccf:"fields" json:"fields" yaml:"fields"`                   // 字段名的匹配模式(path.Match语法)，不区分大小写
+	Values      []string `ccf:"values" json:"values" yaml:"values"`                   // 字符串值中需要脱敏的正则
+	Mask        string   `ccf:"mask" json:"mask" yaml:"mask"`                         // 默认"***"
+}
+
+var (
+	DefaultRedactFields = []string{"*password*", "*passwd*", "*secret*", "*token*", "*api_key*", "*apikey*", "authorization", "cookie"}
+	DefaultRedactValues = []string{
+		`(?i)bearer\s+[a-z0-9\-._~+/]+=*`, // bearer token
+		`\b(?:\d[ -]?){12,18}\d\b`,        // 银行卡号
+	}
+)
+
+const redactMaxDepth = 32
+
+type redactor struct {
+	fields []string
+	values []*regexp.Regexp
+	mask   string
+}
+
+func newRedactor(cfg *RedactConfig) (r *redactor, err error) {
+	if cfg == nil {
+		return
+	}
+	r = &redactor{mask: cfg.Mask}
+	if r.mask == "" {
+		r.mask = "***"
+	}
+	fields, values := cfg.Fields, cfg.Values
+	if cfg.UseDefaults {
+		fields = append(append([]string(nil), DefaultRedactFields...), fields...)
+		values = append(append([]string(nil), DefaultRedactValues...), values...)
+	}
+	for _, pattern := range fields {
+		pattern = strings.ToLower(pattern)
+		if _, err = path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("redact field pattern %s: %w", pattern, err)
+		}
+		r.fields = append(r.fields, pattern)
+	}
+	for _, expr := range values {
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			return nil, fmt.Errorf("redact value regexp %s: %w", expr, err)
+		}
+		r.values = append(r.values, re)
+	}
+	return
+}
+
+func (r *redactor) matchName(name string) bool {
+	name = strings.ToLower(name)
+	for _, pattern := range r.fields {
+		if ok, _ := path.Match(pattern, name); ok {
+			return true
+		}
+	}
+	return false
+}
+
+func (r *redactor) redactString(s string) string {
+	for _, re := range r.values {
+		s = re.ReplaceAllLiteralString(s, r.mask)
+	}
+	return s
+}
+
+func (r *redactor) field(f zapcore.Field) zapcore.Field {
+	switch f.Type {
+	case zapcore.NamespaceType, zapcore.SkipType:
+		return f
+	}
+	if r.matchName(f.Key) {
+		return zap.String(f.Key, r.mask)
+	}
+	switch f.Type {
+	case zapcore.StringType:
+		if s := r.redactString(f.String); s != f.String {
+			return zap.String(f.Key, s)
+		}
+	case zapcore.ErrorType:
+		if err, ok := f.Interface.(error); ok && err != nil {
+			if s := r.redactString(err.Error()); s != err.Error() {
+				return zap.String(f.Key, s)
+			}
+		}
+	case zapcore.StringerType:
+		s := fmt.Sprint(f.Interface)
+		if redacted := r.redactString(s); redacted != s {
+			return zap.String(f.Key, redacted)
+		}
+	case zapcore.ByteStringType:
+		if b, ok := f.Interface.([]byte); ok {
+			if s := r.redactString(string(b)); s != string(b) {
+				return zap.ByteString(f.Key, []byte(s))
+			}
+		}
+	case zapcore.ReflectType:
+		return r.anyField(f.Key, r.value(reflect.ValueOf(f.Interface), 0))
+	case zapcore.ObjectMarshalerType:
+		enc := zapcore.NewMapObjectEncoder()
+		if err := enc.AddObject(f.Key, f.Interface.(zapcore.ObjectMarshaler)); err != nil {
+			return f
+		}
+		obj, _ := enc.Fields[f.Key].(map[string]any)
+		tagged := taggedFields(reflect.TypeOf(f.Interface))
+		for k := range obj {
+			if tagged[strings.ToLower(k)] {
+				obj[k] = r.mask
+			}
+		}
+		return r.anyField(f.Key, r.value(reflect.ValueOf(enc.Fields[f.Key]), 0))
+	case zapcore.ArrayMarshalerType:
+		enc := zapcore.NewMapObjectEncoder()
+		if err := enc.AddArray(f.Key, f.Interface.(zapcore.ArrayMarshaler)); err != nil {
+			return f
+		}
+		return r.anyField(f.Key, r.value(reflect.ValueOf(enc.Fields[f.Key]), 0))
+	}
+	return f
+}
+
+// 结构体中标记了redact:"true"的字段的json名与字段名(小写)
+func taggedFields(t reflect.Type) (names map[string]bool) {
+	for t != nil && t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return
+	}
+	for i := range t.NumField() {
+		sf := t.Field(i)
+		if sf.Tag.Get("redact") != "true" {
+			continue
+		}
+		if names == nil {
+			names = map[string]bool{}
+		}
+		names[strings.ToLower(sf.Name)] = true
+		if name, _, _ := strings.Cut(sf.Tag.Get("json"), ","); name != "" && name != "-" {
+			names[strings.ToLower(name)] = true
+		}
+	}
+	return
+}
+
+func (r *redactor) redactFields(fields []zapcore.Field) []zapcore.Field {
+	out := make([]zapcore.Field, len(fields))
+	for i, f := range fields {
+		out[i] = r.field(f)
+	}
+	return out
+}
+
+func (r *redactor) anyField(key string, v any) zapcore.Field {
+	switch v := v.(type) {
+	case redactedObject:
+		return zap.Object(key, v)
+	case redactedArray:
+		return zap.Array(key, v)
+	default:
+		return zap.Any(key, v)
+	}
+}
+
+var (
+	jsonMarshalerType = reflect.TypeFor[json.Marshaler]()
+	textMarshalerType = reflect.TypeFor[encoding.TextMarshaler]()
+	jsonNumberType    = reflect.TypeFor[json.Number]()
+)
+
+// 按自定义的序列化结果脱敏：json.Marshaler解码后按对象处理，TextMarshaler按字符串处理
+func (r *redactor) marshaled(v any, depth int) any {
+	if m, ok := v.(json.Marshaler); ok {
+		data, err := m.MarshalJSON()
+		if err != nil {
+			return r.mask
+		}
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.UseNumber()
+		var decoded any
+		if err = dec.Decode(&decoded); err != nil {
+			return r.mask
+		}
+		return r.value(reflect.ValueOf(decoded), depth+1)
+	}
+	text, err := v.(encoding.TextMarshaler).MarshalText()
+	if err != nil {
+		return r.mask
+	}
+	return r.redactString(string(text))
+}
+
+// 将任意值转换为脱敏后的对象，结构体字段名按照json tag
+func (r *redactor) value(v reflect.Value, depth int) any {
+	if !v.IsValid() {
+		return nil
+	}
+	if depth > redactMaxDepth {
+		return r.mask
+	}
+	t := v.Type()
+	if t == jsonNumberType {
+		return v.Interface()
+	}
+	if t.Kind() != reflect.Interface && t.Kind() != reflect.Pointer && v.CanInterface() {
+		if t.Implements(jsonMarshalerType) || t.Implements(textMarshalerType) {
+			return r.marshaled(v.Interface(), depth)
+		}
+		if pt := reflect.PointerTo(t); v.CanAddr() && (pt.Implements(jsonMarshalerType) || pt.Implements(textMarshalerType)) {
+			return r.marshaled(v.Addr().Interface(), depth)
+		}
+	}
+
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if v.IsNil() {
+			return nil
+		}
+		return r.value(v.Elem(), depth+1)
+	case reflect.String:
+		return r.redactString(v.String())
+	case reflect.Struct:
+		var obj redactedObject
+		r.appendStruct(&obj, v, depth)
+		return obj
+	case reflect.Map:
+		if v.IsNil() {
+			return nil
+		}
+		keys := v.MapKeys()
+		names := make([]string, len(keys))
+		for i, k := range keys {
+			names[i] = fmt.Sprint(k.Interface())
+		}
+		idx := make([]int, len(keys))
+		for i := range idx {
+			idx[i] = i
+		}
+		sort.Slice(idx, func(a, b int) bool { return names[idx[a]] < names[idx[b]] })
+		obj := make(redactedObject, 0, len(keys))
+		for _, i := range idx {
+			var val any = r.mask
+			if !r.matchName(names[i]) {
+				val = r.value(v.MapIndex(keys[i]), depth+1)
+			}
+			obj = append(obj, redactedKV{key: names[i], value: val})
+		}
+		return obj
+	case reflect.Slice, reflect.Array:
+		if v.Kind() == reflect.Slice && (v.IsNil() || t.Elem().Kind() == reflect.Uint8) {
+			return v.Interface()
+		}
+		arr := make(redactedArray, v.Len())
+		for i := range arr {
+			arr[i] = r.value(v.Index(i), depth+1)
+		}
+		return arr
+	default:
+		if v.CanInterface() {
+			return v.Interface()
+		}
+		return nil
+	}
+}
+
+func (r *redactor) appendStruct(obj *redactedObject, v reflect.Value, depth int) {
+	t := v.Type()
+	for i := range t.NumField() {
+		sf := t.Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+		name, opts, _ := strings.Cut(sf.Tag.Get("json"), ",")
+		if name == "-" && opts == "" {
+			continue
+		}
+		fv := v.Field(i)
+		if sf.Anonymous && name == "" && fv.Kind() == reflect.Struct {
+			r.appendStruct(obj, fv, depth+1)
+			continue
+		}
+		if name == "" {
+			name = sf.Name
+		}
+		if strings.Contains(opts, "omitempty") && fv.IsZero() {
+			continue
+		}
+		var val any = r.mask
+		if sf.Tag.Get("redact") != "true" && !r.matchName(name) {
+			val = r.value(fv, depth+1)
+		}
+		*obj = append(*obj, redactedKV{key: name, value: val})
+	}
+}
+
+type redactedKV struct {
+	key   string
+	value any
+}
+
+// 保持字段顺序的对象
+type redactedObject []redactedKV
+
+func (o redactedObject) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	for _, kv := range o {
+		switch v := kv.value.(type) {
+		case redactedObject:
+			if err := enc.AddObject(kv.key, v); err != nil {
+				return err
+			}
+		case redactedArray:
+			if err := enc.AddArray(kv.key, v); err != nil {
+				return err
+			}
+		case string:
+			enc.AddString(kv.key, v)
+		default:
+			if err := enc.AddReflected(kv.key, v); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+type redactedArray []any
+
+func (a redactedArray) MarshalLogArray(enc zapcore.ArrayEncoder) error {
+	for _, item := range a {
+		switch v := item.(type) {
+		case redactedObject:
+			if err := enc.AppendObject(v); err != nil {
+				return err
+			}
+		case redactedArray:
+			if err := enc.AppendArray(v); err != nil {
+				return err
+			}
+		case string:
+			enc.AppendString(v)
+		default:
+			if err := enc.AppendReflected(v); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// 包装写入sink的core，对消息与字段脱敏
+type redactCore struct {
+	zapcore.Core
+	redactor *redactor
+}
+
+func (r *redactor) wrap(core zapcore.Core) zapcore.Core {
+	if r == nil {
+		return core
+	}
+	return &redactCore{Core: core, redactor: r}
+}
+
+func (c *redactCore) With(fields []zapcore.Field) zapcore.Core {
+	return &redactCore{Core: c.Core.With(c.redactor.redactFields(fields)), redactor: c.redactor}
+}
+
+func (c *redactCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+func (c *redactCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	ent.Message = c.redactor.redactString(ent.Message)
+	return c.Core.Write(ent, c.redactor.redactFields(fields))
+}
@@ -0,0 +1,114 @@
+package compcontzap
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+type redactTestConfig struct {
+	User     string `json:"user"`
+	Password string `json:"password"`
+	DSN      string `json:"dsn" redact:"true"`
+	Headers  map[string]string
+}
+
+func TestRedact(t *testing.T) {
+	encoding := "json"
+	logger, err := New(Config{
+		BaseConfig: "production",
+		ExtraConfig: ExtraConfig{
+			Encoding:    &encoding,
+			OutputPaths: []string{"memory://test-redact"},
+			Redact:      &RedactConfig{UseDefaults: true},
+		},
+	})
+	assert.NoError(t, err)
+	defer Close(logger)
+
+	logger.With(zap.String("token", "abc")).Info("card 4111 1111 1111 1111",
+		zap.String("auth", "Bearer abc.def"),
+		zap.Any("config", redactTestConfig{
+			User:     "admin",
+			Password: "p",
+			DSN:      "mysql://admin:p@db",
+			Headers:  map[string]string{"Authorization": "x", "Accept": "json"},
+		}),
+	)
+
+	recent := GetRingBuffer("test-redact").Recent(0)
+	assert.Len(t, recent, 1)
+	assert.Contains(t, recent[0], `"msg":"card ***"`)
+	assert.Contains(t, recent[0], `"token":"***"`)
+	assert.Contains(t, recent[0], `"auth":"***"`)
+	assert.Contains(t, recent[0], `"config":{"user":"admin","password":"***","dsn":"***","Headers":{"Accept":"json","Authorization":"***"}}`)
+}
+
+type redactTestObject struct {
+	User   string
+	APIKey string `json:"key" redact:"true"`
+}
+
+func (o redactTestObject) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	enc.AddString("user", o.User)
+	enc.AddString("key", o.APIKey)
+	return nil
+}
+
+type redactTestStringer string
+
+func (s redactTestStringer) String() string {
+	return "card " + string(s)
+}
+
+type redactTestJSON struct{}
+
+func (redactTestJSON) MarshalJSON() ([]byte, error) {
+	return []byte(`{"password":"p","note":"Bearer abc","count":3}`), nil
+}
+
+type redactTestText struct{}
+
+func (redactTestText) MarshalText() ([]byte, error) {
+	return []byte("Bearer xyz"), nil
+}
+
+func TestRedactMarshalers(t *testing.T) {
+	encoding := "json"
+	logger, err := New(Config{
+		BaseConfig: "production",
+		ExtraConfig: ExtraConfig{
+			Encoding:    &encoding,
+			OutputPaths: []string{"memory://test-redact-marshalers"},
+			Redact:      &RedactConfig{UseDefaults: true},
+		},
+	})
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer Close(logger)
+	ring := GetRingBuffer("test-redact-marshalers")
+	ring.Reset()
+
+	for _, c := range []struct {
+		field zap.Field
+		want  string
+	}{
+		{zap.Object("obj", redactTestObject{User: "admin", APIKey: "k"}), `"obj":{"key":"***","user":"admin"}`},
+		{zap.Stringer("s", redactTestStringer("4111 1111 1111 1111")), `"s":"card ***"`},
+		{zap.ByteString("b", []byte("Bearer abc")), `"b":"***"`},
+		{zap.Strings("arr", []string{"ok", "Bearer abc"}), `"arr":["ok","***"]`},
+		{zap.Any("json", redactTestJSON{}), `"json":{"count":3,"note":"***","password":"***"}`},
+		{zap.Any("text", redactTestText{}), `"text":"***"`},
+		{zap.Any("nested", struct{ Inner redactTestJSON }{}), `"nested":{"Inner":{"count":3,"note":"***","password":"***"}}`},
+	} {
+		ring.Reset()
+		logger.Info("marshalers", c.field)
+		recent := ring.Recent(0)
+		if assert.Len(t, recent, 1, c.field.Key) {
+			assert.Contains(t, recent[0], c.want, c.field.Key)
+		}
+	}
+}
@@ -0,0 +1,269 @@
+package compcontzap
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"slices"
+	"sync"
+	"sync/atomic"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+type coreGeneration struct {
+	core    zapcore.Core
+	errSink zapcore.WriteSyncer
+	gen     uint64
+
+	mu      sync.RWMutex // 写入时持有读锁，退役时持有写锁，保证关闭sink前没有进行中的写入
+	retired bool
+}
+
+// 停止使用该代core并刷新，之后才能关闭其sink
+func (g *coreGeneration) retire() {
+	g.mu.Lock()
+	g.retired = true
+	g.mu.Unlock()
+	_ = g.core.Sync()
+}
+
+// 同一个logger及其派生的所有logger共享的状态，重建时整体替换current
+type loggerState struct {
+	levels   *LevelController // 重建前后保持不变，仅修改级别时无需重建
+	dropped  *DropStats       // 重建前后持续累计
+	attached []zapcore.Core   // 重建时保留
+	current  atomic.Pointer[coreGeneration]
+
+	mu        sync.Mutex
+	config    Config
+	closeFunc func()
+	onClose   []func()
+}
+
+func configLevel(finalCfg zap.Config) zapcore.Level {
+	if finalCfg.Level == (zap.AtomicLevel{}) {
+		return zap.InfoLevel
+	}
+	return finalCfg.Level.Level()
+}
+
+func newLoggerState(cfg Config, finalCfg zap.Config, attached []zapcore.Core) (s *loggerState, err error) {
+	named, err := parseNamedLevels(cfg.ExtraConfig.NamedLevels)
+	if err != nil {
+		return
+	}
+	levels := newLevelController(configLevel(finalCfg))
+	levels.setNamedLevels(named)
+	dropped := &DropStats{}
+	finalCfg.Level = levels.AtomicLevel()
+	core, errSink, closeFunc, err := buildCore(finalCfg, cfg, attached, levels, dropped)
+	if err != nil {
+		return
+	}
+	s = &loggerState{
+		levels:    levels,
+		dropped:   dropped,
+		attached:  attached,
+		config:    cfg,
+		closeFunc: closeFunc,
+	}
+	s.current.Store(&coreGeneration{core: core, errSink: errSink})
+	return
+}
+
+// 去掉级别后比较配置是否一致，一致时只需修改级别
+func sameExceptLevel(a, b Config) bool {
+	a.ExtraConfig.Level, b.ExtraConfig.Level = nil, nil
+	a.ExtraConfig.NamedLevels, b.ExtraConfig.NamedLevels = nil, nil
+	a.Default, b.Default = false, false
+	a.SlogDefault, b.SlogDefault = false, false
+	a.DefaultPriority, b.DefaultPriority = 0, 0
+	a.ReplaceGlobals, b.ReplaceGlobals = false, false
+	a.Reloading, b.Reloading = nil, nil
+	a.Alerts, b.Alerts = nil, nil
+	return reflect.DeepEqual(a, b)
+}
+
+func (s *loggerState) reload(cfg Config) (err error) {
+	finalCfg, err := cfg.zapConfig()
+	if err != nil {
+		return
+	}
+	named, err := parseNamedLevels(cfg.ExtraConfig.NamedLevels)
+	if err != nil {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !sameExceptLevel(s.config, cfg) {
+		finalCfg.Level = s.levels.AtomicLevel()
+		core, errSink, closeFunc, err1 := buildCore(finalCfg, cfg, s.attached, s.levels, s.dropped)
+		if err1 != nil {
+			return err1
+		}
+		old := s.current.Load()
+		s.current.Store(&coreGeneration{core: core, errSink: errSink, gen: old.gen + 1})
+		// 等待旧core上进行中的写入完成后再关闭旧的sink
+		old.retire()
+		s.closeFunc()
+		s.closeFunc = closeFunc
+	}
+	s.levels.setBaseLevel(configLevel(finalCfg))
+	s.levels.setNamedLevels(named)
+	s.config = cfg
+	return
+}
+
+func (s *loggerState) close() (err error) {
+	// 回调中会移除reloading的监听，而reloading回调时会调用reload获取s.mu，因此在锁外执行
+	s.mu.Lock()
+	onClose := s.onClose
+	s.onClose = nil
+	s.mu.Unlock()
+	for _, fn := range onClose {
+		fn()
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.levels.stop()
+	current := s.current.Load()
+	err = current.core.Sync()
+	if s.closeFunc != nil {
+		// 关闭之后的写入被丢弃
+		s.current.Store(&coreGeneration{core: zapcore.NewNopCore(), errSink: zapcore.AddSync(io.Discard), gen: current.gen + 1})
+		current.retire()
+		s.closeFunc()
+		s.closeFunc = nil
+	}
+	return
+}
+
+// logger的根core，实际写入委托给当前的core，重建后所有派生logger(With/Named)都会使用新的core
+type loggerCore struct {
+	state  *loggerState
+	fields []zapcore.Field
+	cache  atomic.Pointer[coreGeneration] // 附加了fields的core，按代缓存
+}
+
+// 获取当前代的core并持有其读锁，调用方写入后需释放
+func (s *loggerState) acquire() *coreGeneration {
+	for {
+		g := s.current.Load()
+		g.mu.RLock()
+		if !g.retired {
+			return g
+		}
+		g.mu.RUnlock() // 已被替换，重新获取
+	}
+}
+
+func (c *loggerCore) core(current *coreGeneration) zapcore.Core {
+	if len(c.fields) == 0 {
+		return current.core
+	}
+	if cached := c.cache.Load(); cached != nil && cached.gen == current.gen {
+		return cached.core
+	}
+	core := current.core.With(c.fields)
+	c.cache.Store(&coreGeneration{core: core, gen: current.gen})
+	return core
+}
+
+func (c *loggerCore) Enabled(lvl zapcore.Level) bool {
+	return c.state.levels.Enabled(lvl)
+}
+
+// 实现zapcore.LevelOf使用的接口
+func (c *loggerCore) Level() zapcore.Level {
+	return c.state.levels.Level()
+}
+
+func (c *loggerCore) With(fields []zapcore.Field) zapcore.Core {
+	return &loggerCore{
+		state:  c.state,
+		fields: append(slices.Clip(c.fields), fields...),
+	}
+}
+
+func (c *loggerCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if !c.state.levels.EnabledFor(ent.LoggerName, ent.Level) {
+		return ce
+	}
+	return ce.AddCore(ent, c)
+}
+
+// 写入时才选择当前代的core，保证重建后旧sink关闭时没有仍在使用它的写入
+func (c *loggerCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	g := c.state.acquire()
+	defer g.mu.RUnlock()
+	if ce := c.core(g).Check(ent, nil); ce != nil {
+		ce.ErrorOutput = g.errSink
+		ce.Write(fields...)
+	}
+	return nil
+}
+
+func (c *loggerCore) Sync() error {
+	return c.state.current.Load().core.Sync()
+}
+
+// logger内部错误的输出，跟随core一起替换
+type errorOutput struct {
+	state *loggerState
+}
+
+func (e *errorOutput) Write(p []byte) (int, error) {
+	g := e.state.acquire()
+	defer g.mu.RUnlock()
+	return g.errSink.Write(p)
+}
+
+func (e *errorOutput) Sync() error {
+	return e.state.current.Load().errSink.Sync()
+}
+
+var errNotReloadable = errors.New("logger is not created by compcontzap")
+
+func stateOf(l *zap.Logger) (*loggerState, error) {
+	c, ok := l.Core().(*loggerCore)
+	if !ok {
+		return nil, fmt.Errorf("%w, core type: %T", errNotReloadable, l.Core())
+	}
+	return c.state, nil
+}
+
+// Reload 使用新的配置重建由New创建的logger，持有该logger及其派生logger的地方无需替换指针。
+// 仅级别变化时直接修改级别，否则重建encoder与sink并替换core
+func Reload(l *zap.Logger, cfg Config) error {
+	s, err := stateOf(l)
+	if err != nil {
+		return err
+	}
+	return s.reload(cfg)
+}
+
+// OnClose 注册logger关闭时的回调
+func OnClose(l *zap.Logger, fn func()) error {
+	s, err := stateOf(l)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.onClose = append(s.onClose, fn)
+	return nil
+}
+
+// Close 关闭由New创建的logger，刷新并关闭其所有sink
+func Close(l *zap.Logger) error {
+	s, err := stateOf(l)
+	if err != nil {
+		return err
+	}
+	return s.close()
+}
@@ -0,0 +1,98 @@
+package compcontzap
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReloadInvalidBaseConfig(t *testing.T) {
+	_, err := New(Config{BaseConfig: "unknown"})
+	assert.Error(t, err)
+
+	l, err := New(Config{BaseConfig: "production", ExtraConfig: ExtraConfig{OutputPaths: []string{"memory://test-reload-invalid"}}})
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer Close(l)
+	assert.Error(t, Reload(l, Config{BaseConfig: "unknown"}))
+}
+
+func TestReloadWhileWriting(t *testing.T) {
+	dir := t.TempDir()
+	cfg := func(i int) Config {
+		return Config{BaseConfig: "production", ExtraConfig: ExtraConfig{
+			OutputPaths:      []string{filepath.Join(dir, "out"+string(rune('a'+i%2))+".log")},
+			ErrorOutputPaths: []string{"memory://test-reload-errors"},
+		}}
+	}
+	l, err := New(cfg(0))
+	if !assert.NoError(t, err) {
+		return
+	}
+	errs := GetRingBuffer("test-reload-errors")
+	errs.Reset()
+
+	var wg sync.WaitGroup
+	stop := make(chan struct{})
+	for range 4 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+					l.Info("message")
+				}
+			}
+		}()
+	}
+	for i := 1; i <= 50; i++ {
+		assert.NoError(t, Reload(l, cfg(i)))
+		l.Info("message")
+	}
+	close(stop)
+	wg.Wait()
+	assert.NoError(t, Close(l))
+
+	// 旧sink关闭时不应有进行中的写入
+	assert.Empty(t, errs.Recent(0))
+	a, _ := os.ReadFile(filepath.Join(dir, "outa.log"))
+	b, _ := os.ReadFile(filepath.Join(dir, "outb.log"))
+	assert.True(t, strings.Contains(string(a), "message") && strings.Contains(string(b), "message"))
+
+	l.Info("after close") // 关闭后的写入被丢弃
+	assert.Empty(t, errs.Recent(0))
+}
+
+func TestCloseCallbackReload(t *testing.T) {
+	cfg := Config{BaseConfig: "production", ExtraConfig: ExtraConfig{OutputPaths: []string{"memory://test-close-callback"}}}
+	l, err := New(cfg)
+	if !assert.NoError(t, err) {
+		return
+	}
+	// 关闭回调中获取其他锁时，持有那些锁的一方可能正在调用Reload，回调期间不能持有logger的锁
+	var reloadErr error
+	assert.NoError(t, OnClose(l, func() {
+		reloadErr = Reload(l, cfg)
+	}))
+
+	closed := make(chan error, 1)
+	go func() {
+		closed <- Close(l)
+	}()
+	select {
+	case err = <-closed:
+		assert.NoError(t, err)
+		assert.NoError(t, reloadErr)
+	case <-time.After(5 * time.Second):
+		assert.Fail(t, "Close deadlocked")
+	}
+}
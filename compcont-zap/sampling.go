@@ -0,0 +1,242 @@
+package compcontzap
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// 采样与限流配置，例如
+//
+//	sampling:
+//	  initial: 100
+//	  thereafter: 100
+//	  tick: 1s
+//	  rate_limit: { per_message: 10, interval: 1m }
+type SamplingConfig struct {
+	Disabled   bool          `ccf:"disabled" json:"disabled" yaml:"disabled"`       // 关闭采样，包括production基础配置中的采样
+	Initial    *int          `ccf:"initial" json:"initial" yaml:"initial"`          // 每个tick内相同级别与消息的前initial条全部输出
+	Thereafter *int          `ccf:"thereafter" json:"thereafter" yaml:"thereafter"` // 之后每thereafter条输出一条
+	Tick       time.Duration `ccf:"tick" json:"tick" yaml:"tick"`                   // 默认1s
+
+	RateLimit *RateLimitConfig `ccf:"rate_limit" json:"rate_limit" yaml:"rate_limit"`
+}
+
+// 按消息限流，每个interval内相同级别与消息最多输出per_message条，
+// 超出的部分丢弃，并在interval结束时输出一条"dropped N messages"的汇总
+type RateLimitConfig struct {
+	PerMessage   uint64        `ccf:"per_message" json:"per_message" yaml:"per_message"`
+	Interval     time.Duration `ccf:"interval" json:"interval" yaml:"interval"`                // 默认1s
+	SummaryLevel string        `ccf:"summary_level" json:"summary_level" yaml:"summary_level"` // 汇总日志的级别，默认warn
+}
+
+func (c *SamplingConfig) mergeTo(output *zap.Config) {
+	if c.Disabled {
+		output.Sampling = nil
+		return
+	}
+	if c.Initial == nil && c.Thereafter == nil {
+		return
+	}
+	sampling := &zap.SamplingConfig{Initial: 100, Thereafter: 100}
+	if output.Sampling != nil {
+		*sampling = *output.Sampling
+	}
+	if c.Initial != nil {
+		sampling.Initial = *c.Initial
+	}
+	if c.Thereafter != nil {
+		sampling.Thereafter = *c.Thereafter
+	}
+	output.Sampling = sampling
+}
+
+// 被丢弃的日志条数，重建logger后继续累计
+type DropStats struct {
+	sampled     atomic.Uint64
+	rateLimited atomic.Uint64
+	overflowed  atomic.Uint64
+}
+
+// 被采样丢弃的条数
+func (s *DropStats) Sampled() uint64 {
+	return s.sampled.Load()
+}
+
+// 被限流丢弃的条数
+func (s *DropStats) RateLimited() uint64 {
+	return s.rateLimited.Load()
+}
+
+// 异步写入队列满时被丢弃的条数
+func (s *DropStats) Overflowed() uint64 {
+	return s.overflowed.Load()
+}
+
+func (s *DropStats) samplerHook(ent zapcore.Entry, dec zapcore.SamplingDecision) {
+	if dec&zapcore.LogDropped != 0 {
+		s.sampled.Add(1)
+	}
+}
+
+// Dropped 获取由New创建的logger的丢弃统计
+func Dropped(l *zap.Logger) (*DropStats, error) {
+	s, err := stateOf(l)
+	if err != nil {
+		return nil, err
+	}
+	return s.dropped, nil
+}
+
+// 在core外包装采样与限流
+func wrapSampling(core zapcore.Core, finalCfg zap.Config, cfg *SamplingConfig, stats *DropStats) (_ zapcore.Core, stop func(), err error) {
+	stop = func() {}
+	if scfg := finalCfg.Sampling; scfg != nil {
+		tick := time.Second
+		if cfg != nil && cfg.Tick > 0 {
+			tick = cfg.Tick
+		}
+		hook := stats.samplerHook
+		if scfg.Hook != nil {
+			hook = func(ent zapcore.Entry, dec zapcore.SamplingDecision) {
+				stats.samplerHook(ent, dec)
+				scfg.Hook(ent, dec)
+			}
+		}
+		core = zapcore.NewSamplerWithOptions(core, tick, scfg.Initial, scfg.Thereafter, zapcore.SamplerHook(hook))
+	}
+	if cfg == nil || cfg.RateLimit == nil {
+		return core, stop, nil
+	}
+
+	rl := cfg.RateLimit
+	if rl.PerMessage == 0 {
+		err = fmt.Errorf("rate_limit.per_message must be greater than 0")
+		return
+	}
+	summaryLevel := zapcore.WarnLevel
+	if rl.SummaryLevel != "" {
+		if summaryLevel, err = zapcore.ParseLevel(rl.SummaryLevel); err != nil {
+			return
+		}
+	}
+	interval := rl.Interval
+	if interval <= 0 {
+		interval = time.Second
+	}
+	limiter := newRateLimiter(core, rl.PerMessage, interval, summaryLevel, stats)
+	return &rateLimitCore{Core: core, limiter: limiter}, limiter.stop, nil
+}
+
+type rateKey struct {
+	level   zapcore.Level
+	logger  string
+	message string
+}
+
+type rateLimiter struct {
+	out          zapcore.Core // 汇总日志写入的core
+	limit        uint64
+	summaryLevel zapcore.Level
+	stats        *DropStats
+
+	mu     sync.Mutex
+	counts map[rateKey]uint64
+
+	ticker *time.Ticker
+	done   chan struct{}
+	wg     sync.WaitGroup
+}
+
+func newRateLimiter(out zapcore.Core, limit uint64, interval time.Duration, summaryLevel zapcore.Level, stats *DropStats) *rateLimiter {
+	r := &rateLimiter{
+		out:          out,
+		limit:        limit,
+		summaryLevel: summaryLevel,
+		stats:        stats,
+		counts:       map[rateKey]uint64{},
+		ticker:       time.NewTicker(interval),
+		done:         make(chan struct{}),
+	}
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+		for {
+			select {
+			case <-r.ticker.C:
+				r.flush()
+			case <-r.done:
+				return
+			}
+		}
+	}()
+	return r
+}
+
+func (r *rateLimiter) allow(ent zapcore.Entry) bool {
+	key := rateKey{level: ent.Level, logger: ent.LoggerName, message: ent.Message}
+	r.mu.Lock()
+	n := r.counts[key] + 1
+	r.counts[key] = n
+	r.mu.Unlock()
+	if n > r.limit {
+		r.stats.rateLimited.Add(1)
+		return false
+	}
+	return true
+}
+
+// 开始新的周期，并输出上个周期的丢弃汇总
+func (r *rateLimiter) flush() {
+	r.mu.Lock()
+	counts := r.counts
+	r.counts = map[rateKey]uint64{}
+	r.mu.Unlock()
+
+	for key, n := range counts {
+		if n <= r.limit {
+			continue
+		}
+		dropped := n - r.limit
+		ent := zapcore.Entry{
+			Level:      r.summaryLevel,
+			Time:       time.Now(),
+			LoggerName: key.logger,
+			Message:    fmt.Sprintf("dropped %d messages", dropped),
+		}
+		if ce := r.out.Check(ent, nil); ce != nil {
+			ce.Write(
+				zap.String("dropped_message", key.message),
+				zap.Stringer("dropped_level", key.level),
+				zap.Uint64("dropped", dropped),
+			)
+		}
+	}
+}
+
+func (r *rateLimiter) stop() {
+	r.ticker.Stop()
+	close(r.done)
+	r.wg.Wait()
+	r.flush()
+}
+
+type rateLimitCore struct {
+	zapcore.Core
+	limiter *rateLimiter
+}
+
+func (c *rateLimitCore) With(fields []zapcore.Field) zapcore.Core {
+	return &rateLimitCore{Core: c.Core.With(fields), limiter: c.limiter}
+}
+
+func (c *rateLimitCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if !c.Enabled(ent.Level) || !c.limiter.allow(ent) {
+		return ce
+	}
+	return c.Core.Check(ent, ce)
+}
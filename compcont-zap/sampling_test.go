@@ -0,0 +1,45 @@
+package compcontzap
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRateLimit(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "out.log")
+	encoding := "json"
+	logger, err := New(Config{
+		BaseConfig: "production",
+		ExtraConfig: ExtraConfig{
+			Encoding:    &encoding,
+			OutputPaths: []string{file},
+			Sampling: &SamplingConfig{
+				Disabled:  true,
+				RateLimit: &RateLimitConfig{PerMessage: 3, Interval: time.Hour},
+			},
+		},
+	})
+	assert.NoError(t, err)
+
+	for range 10 {
+		logger.Info("flood")
+	}
+	logger.Info("other")
+
+	dropped, err := Dropped(logger)
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(7), dropped.RateLimited())
+	assert.Equal(t, uint64(0), dropped.Sampled())
+
+	assert.NoError(t, Close(logger))
+	out, err := os.ReadFile(file)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, strings.Count(string(out), `"msg":"flood"`))
+	assert.Contains(t, string(out), `"msg":"other"`)
+	assert.Contains(t, string(out), `"msg":"dropped 7 messages"`)
+}
@@ -0,0 +1,566 @@
+package compcontzap
+
+import (
+	"cmp"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// 与lumberjack相同的路径约定，host为relative-path或absolute-path
+func sinkFilename(u *url.URL) (string, error) {
+	switch u.Hostname() {
+	case "relative-path":
+		return "." + u.Path, nil
+	case "absolute-path":
+		return u.Path, nil
+	default:
+		return "", fmt.Errorf("unknown hostname: %s", u.Hostname())
+	}
+}
+
+func queryInt(q url.Values, key string, def int) (int, error) {
+	str := q.Get(key)
+	if str == "" {
+		return def, nil
+	}
+	return strconv.Atoi(str)
+}
+
+func queryDuration(q url.Values, key string) (time.Duration, error) {
+	str := q.Get(key)
+	if str == "" {
+		return 0, nil
+	}
+	return time.ParseDuration(str)
+}
+
+var syslogFacilities = map[string]int{
+	"kern": 0, "user": 1, "mail": 2, "daemon": 3, "auth": 4, "syslog": 5, "lpr": 6, "news": 7,
+	"uucp": 8, "cron": 9, "authpriv": 10, "ftp": 11,
+	"local0": 16, "local1": 17, "local2": 18, "local3": 19, "local4": 20, "local5": 21, "local6": 22, "local7": 23,
+}
+
+var syslogSeverities = map[string]int{
+	"emerg": 0, "alert": 1, "crit": 2, "err": 3, "warning": 4, "notice": 5, "info": 6, "debug": 7,
+}
+
+// 日志级别对应的syslog severity
+func syslogSeverity(lvl zapcore.Level) int {
+	switch {
+	case lvl <= zapcore.DebugLevel:
+		return syslogSeverities["debug"]
+	case lvl == zapcore.InfoLevel:
+		return syslogSeverities["info"]
+	case lvl == zapcore.WarnLevel:
+		return syslogSeverities["warning"]
+	case lvl == zapcore.ErrorLevel:
+		return syslogSeverities["err"]
+	default:
+		return syslogSeverities["crit"]
+	}
+}
+
+// 以RFC3164格式写入syslog，每次Write对应一条日志
+type syslogWriter struct {
+	network, addr string
+	facility      int
+	severity      int // 不经过syslogCore写入时使用的severity
+	tag           string
+	hostname      string // 为空表示本地syslog，不输出hostname
+
+	mu   sync.Mutex
+	conn net.Conn
+}
+
+func (w *syslogWriter) connect() (err error) {
+	if w.conn != nil {
+		return
+	}
+	w.conn, err = net.Dial(w.network, w.addr)
+	return
+}
+
+func (w *syslogWriter) format(severity int, p []byte) []byte {
+	msg := strings.TrimRight(string(p), "\n")
+	ts := time.Now().Format(time.Stamp)
+	priority := w.facility<<3 | severity
+	if w.hostname == "" {
+		return fmt.Appendf(nil, "<%d>%s %s[%d]: %s\n", priority, ts, w.tag, os.Getpid(), msg)
+	}
+	return fmt.Appendf(nil, "<%d>%s %s %s[%d]: %s\n", priority, ts, w.hostname, w.tag, os.Getpid(), msg)
+}
+
+func (w *syslogWriter) Write(p []byte) (int, error) {
+	return w.writeSeverity(w.severity, p)
+}
+
+func (w *syslogWriter) writeSeverity(severity int, p []byte) (n int, err error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	data := w.format(severity, p)
+	// 连接断开(例如syslog重启)时重连一次
+	for range 2 {
+		if err = w.connect(); err != nil {
+			return
+		}
+		if _, err = w.conn.Write(data); err == nil {
+			return len(p), nil
+		}
+		_ = w.conn.Close()
+		w.conn = nil
+	}
+	return
+}
+
+func (w *syslogWriter) Sync() error {
+	return nil
+}
+
+func (w *syslogWriter) Close() (err error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.conn != nil {
+		err = w.conn.Close()
+		w.conn = nil
+	}
+	return
+}
+
+// syslog:///dev/log?facility=local0&tag=app 写入本地syslog(unix datagram)，
+// syslog://host:514?network=udp&facility=local0&tag=app 写入远程syslog。
+// 作为output_paths时severity由每条日志的级别决定，
+// severity参数(默认info)只用于error_output_paths等直接写入的场景
+func newSyslogSink(u *url.URL) (sink zap.Sink, err error) {
+	return newSyslogWriter(u)
+}
+
+func newSyslogWriter(u *url.URL) (w *syslogWriter, err error) {
+	q := u.Query()
+	w = &syslogWriter{tag: q.Get("tag")}
+	if w.tag == "" {
+		w.tag = filepath.Base(os.Args[0])
+	}
+	facility, severity := syslogFacilities["user"], syslogSeverities["info"]
+	if f := q.Get("facility"); f != "" {
+		var ok bool
+		if facility, ok = syslogFacilities[f]; !ok {
+			return nil, fmt.Errorf("unknown syslog facility: %s", f)
+		}
+	}
+	if s := q.Get("severity"); s != "" {
+		var ok bool
+		if severity, ok = syslogSeverities[s]; !ok {
+			return nil, fmt.Errorf("unknown syslog severity: %s", s)
+		}
+	}
+	w.facility, w.severity = facility, severity
+
+	if u.Host == "" {
+		w.network, w.addr = "unixgram", u.Path
+	} else {
+		w.network, w.addr = q.Get("network"), u.Host
+		if w.network == "" {
+			w.network = "udp"
+		}
+		if w.hostname, err = os.Hostname(); err != nil {
+			return
+		}
+	}
+	// 启动时不可达不视为错误，写入时重试连接
+	_ = w.connect()
+	return
+}
+
+// 按每条日志的级别写入syslog，不经过缓冲，避免多条日志合并为一个datagram
+type syslogCore struct {
+	zapcore.LevelEnabler
+	enc zapcore.Encoder
+	out *syslogWriter
+}
+
+func (c *syslogCore) With(fields []zapcore.Field) zapcore.Core {
+	enc := c.enc.Clone()
+	for _, f := range fields {
+		f.AddTo(enc)
+	}
+	return &syslogCore{LevelEnabler: c.LevelEnabler, enc: enc, out: c.out}
+}
+
+func (c *syslogCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+func (c *syslogCore) Write(ent zapcore.Entry, fields []zapcore.Field) (err error) {
+	buf, err := c.enc.EncodeEntry(ent, fields)
+	if err != nil {
+		return
+	}
+	_, err = c.out.writeSeverity(syslogSeverity(ent.Level), buf.Bytes())
+	buf.Free()
+	return
+}
+
+func (c *syslogCore) Sync() error {
+	return c.out.Sync()
+}
+
+// 根据输出路径构造core，syslog路径单独构造syslogCore，其余路径共用一个sink
+func newSinkCore(enc zapcore.Encoder, paths []string, buffer *BufferConfig, enabler zapcore.LevelEnabler, dropped *DropStats) (core zapcore.Core, closeFunc func(), err error) {
+	var (
+		cores  []zapcore.Core
+		closes []func()
+		others []string
+	)
+	closeAll := func() {
+		for _, f := range closes {
+			f()
+		}
+	}
+	defer func() {
+		if err != nil {
+			closeAll()
+		}
+	}()
+	for _, path := range paths {
+		u, err1 := url.Parse(path)
+		if err1 != nil || u.Scheme != "syslog" {
+			others = append(others, path)
+			continue
+		}
+		w, err1 := newSyslogWriter(u)
+		if err1 != nil {
+			err = fmt.Errorf("open sink %q: %w", path, err1)
+			return
+		}
+		closes = append(closes, func() { _ = w.Close() })
+		cores = append(cores, &syslogCore{LevelEnabler: enabler, enc: enc.Clone(), out: w})
+	}
+	if len(others) > 0 || len(cores) == 0 {
+		sink, closeSink, err1 := openSink(others, buffer, dropped)
+		if err1 != nil {
+			err = err1
+			return
+		}
+		closes = append(closes, closeSink)
+		cores = append(cores, zapcore.NewCore(enc, sink, enabler))
+	}
+	return zapcore.NewTee(cores...), closeAll, nil
+}
+
+// unixgram:///path/to/socket 每条日志作为一个datagram原样写入
+func newUnixgramSink(u *url.URL) (zap.Sink, error) {
+	conn, err := net.Dial("unixgram", u.Path)
+	if err != nil {
+		return nil, err
+	}
+	return &sinkFunc{
+		CloseFunc: conn.Close,
+		WriteFunc: conn.Write,
+		SyncFunc: func() error {
+			return nil
+		},
+	}, nil
+}
+
+var rotateLayouts = map[string]string{
+	"hourly": "2006-01-02T15",
+	"daily":  "2006-01-02",
+}
+
+// 按时间切分的文件，文件名为<name>-<周期><ext>，例如app-2024-01-02.log
+type timeRotateWriter struct {
+	dir, name, ext string
+	layout         string
+	localTime      bool
+	maxAge         time.Duration // 超过该时长的旧文件被删除，0表示不限制
+	maxBackups     int           // 保留的旧文件个数，0表示不限制
+
+	mu     sync.Mutex
+	file   *os.File
+	period string
+}
+
+func (w *timeRotateWriter) now() time.Time {
+	if w.localTime {
+		return time.Now()
+	}
+	return time.Now().UTC()
+}
+
+func (w *timeRotateWriter) filename(period string) string {
+	return filepath.Join(w.dir, w.name+"-"+period+w.ext)
+}
+
+// 必须持有mu
+func (w *timeRotateWriter) rotate(period string) (err error) {
+	if w.file != nil {
+		_ = w.file.Close()
+		w.file = nil
+	}
+	if err = os.MkdirAll(w.dir, 0o755); err != nil {
+		return
+	}
+	if w.file, err = os.OpenFile(w.filename(period), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644); err != nil {
+		return
+	}
+	w.period = period
+	w.cleanup()
+	return
+}
+
+// 按保留策略删除旧文件，必须持有mu
+func (w *timeRotateWriter) cleanup() {
+	if w.maxAge <= 0 && w.maxBackups <= 0 {
+		return
+	}
+	matches, err := filepath.Glob(filepath.Join(w.dir, w.name+"-*"+w.ext))
+	if err != nil {
+		return
+	}
+	type backup struct {
+		file string
+		t    time.Time
+	}
+	var backups []backup
+	for _, file := range matches {
+		period := strings.TrimSuffix(strings.TrimPrefix(filepath.Base(file), w.name+"-"), w.ext)
+		if period == w.period {
+			continue
+		}
+		t, err := time.Parse(w.layout, period)
+		if err != nil {
+			continue
+		}
+		backups = append(backups, backup{file: file, t: t})
+	}
+	sort.Slice(backups, func(i, j int) bool { return backups[i].t.After(backups[j].t) })
+	cutoff := w.now().Add(-w.maxAge)
+	for i, b := range backups {
+		if (w.maxBackups > 0 && i >= w.maxBackups) || (w.maxAge > 0 && b.t.Before(cutoff)) {
+			_ = os.Remove(b.file)
+		}
+	}
+}
+
+func (w *timeRotateWriter) Write(p []byte) (n int, err error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if period := w.now().Format(w.layout); w.file == nil || period != w.period {
+		if err = w.rotate(period); err != nil {
+			return
+		}
+	}
+	return w.file.Write(p)
+}
+
+func (w *timeRotateWriter) Sync() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.file == nil {
+		return nil
+	}
+	return w.file.Sync()
+}
+
+func (w *timeRotateWriter) Close() (err error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.file != nil {
+		err = w.file.Close()
+		w.file = nil
+	}
+	return
+}
+
+// rotate://relative-path/logs/app.log?interval=daily&max_age=168h&max_backups=7&local_time=true
+func newTimeRotateSink(u *url.URL) (sink zap.Sink, err error) {
+	filename, err := sinkFilename(u)
+	if err != nil {
+		return
+	}
+	q := u.Query()
+	interval := q.Get("interval")
+	if interval == "" {
+		interval = "daily"
+	}
+	w := &timeRotateWriter{dir: filepath.Dir(filename)}
+	var ok bool
+	if w.layout, ok = rotateLayouts[interval]; !ok {
+		return nil, fmt.Errorf("unknown rotate interval: %s", interval)
+	}
+	w.ext = filepath.Ext(filename)
+	w.name = strings.TrimSuffix(filepath.Base(filename), w.ext)
+	if w.maxAge, err = queryDuration(q, "max_age"); err != nil {
+		return
+	}
+	if w.maxBackups, err = queryInt(q, "max_backups", 0); err != nil {
+		return
+	}
+	if lt := q.Get("local_time"); lt != "" {
+		if w.localTime, err = strconv.ParseBool(lt); err != nil {
+			return
+		}
+	}
+	return w, nil
+}
+
+// 内存中的环形缓冲区，保存最近写入的日志，每次Write对应一条
+type RingBuffer struct {
+	mu      sync.Mutex
+	entries [][]byte
+	next    int
+	full    bool
+	sized   bool // 由指定了size的sink创建或调整过大小
+}
+
+func newRingBuffer(size int) *RingBuffer {
+	return &RingBuffer{entries: make([][]byte, size)}
+}
+
+func (r *RingBuffer) Write(p []byte) (int, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.entries[r.next] = append([]byte(nil), p...)
+	r.next++
+	if r.next == len(r.entries) {
+		r.next, r.full = 0, true
+	}
+	return len(p), nil
+}
+
+func (r *RingBuffer) Sync() error {
+	return nil
+}
+
+// 写入sink的Close不清空缓冲区，以便logger关闭后仍然可以读取
+func (r *RingBuffer) Close() error {
+	return nil
+}
+
+// Recent 按写入顺序返回最近的n条日志，n<=0表示全部
+func (r *RingBuffer) Recent(n int) (entries []string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var ordered [][]byte
+	if r.full {
+		ordered = append(ordered, r.entries[r.next:]...)
+	}
+	ordered = append(ordered, r.entries[:r.next]...)
+	if n > 0 && n < len(ordered) {
+		ordered = ordered[len(ordered)-n:]
+	}
+	for _, e := range ordered {
+		entries = append(entries, string(e))
+	}
+	return
+}
+
+// Reset 清空缓冲区
+func (r *RingBuffer) Reset() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	clear(r.entries)
+	r.next, r.full = 0, false
+}
+
+// ServeHTTP 按原始格式输出最近的日志，?n=100限制条数
+func (r *RingBuffer) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	n, err := strconv.Atoi(req.URL.Query().Get("n"))
+	if req.URL.Query().Get("n") != "" && err != nil {
+		http.Error(w, "invalid n", http.StatusBadRequest)
+		return
+	}
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	for _, e := range r.Recent(n) {
+		_, _ = w.Write([]byte(e))
+	}
+}
+
+var (
+	ringBuffersMu sync.Mutex
+	ringBuffers   = map[string]*RingBuffer{}
+)
+
+// 调整缓冲区大小，保留最近的日志
+func (r *RingBuffer) resize(size int) {
+	recent := r.Recent(size)
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.entries = make([][]byte, size)
+	r.next, r.full = 0, false
+	for _, e := range recent {
+		r.entries[r.next] = []byte(e)
+		r.next++
+	}
+	if r.next == size {
+		r.next, r.full = 0, true
+	}
+}
+
+// GetRingBuffer 获取memory://<name>sink对应的缓冲区，在logger构造之前调用时也会创建，
+// 此时大小为默认的1000，之后由指定了size的sink调整
+func GetRingBuffer(name string) *RingBuffer {
+	r, _ := ringBuffer(name, 0)
+	return r
+}
+
+// size为0表示未指定，使用已有缓冲区或按默认大小创建
+func ringBuffer(name string, size int) (r *RingBuffer, err error) {
+	ringBuffersMu.Lock()
+	defer ringBuffersMu.Unlock()
+	r, ok := ringBuffers[name]
+	switch {
+	case !ok:
+		r = newRingBuffer(cmp.Or(size, 1000))
+		r.sized = size > 0
+		ringBuffers[name] = r
+	case size == 0:
+	case !r.sized:
+		r.resize(size)
+		r.sized = true
+	case len(r.entries) != size:
+		return nil, fmt.Errorf("ring buffer %q already exists with size %d", name, len(r.entries))
+	}
+	return
+}
+
+// memory://recent?size=1000 同名的sink共享同一个缓冲区，
+// 已由其他sink指定了不同的size时返回错误，未指定size时沿用已有缓冲区
+func newRingBufferSink(u *url.URL) (zap.Sink, error) {
+	size, err := queryInt(u.Query(), "size", 0)
+	if err != nil {
+		return nil, err
+	}
+	if size < 0 || (size == 0 && u.Query().Has("size")) {
+		return nil, fmt.Errorf("invalid ring buffer size: %d", size)
+	}
+	return ringBuffer(u.Host, size)
+}
+
+func init() {
+	for scheme, factory := range map[string]func(*url.URL) (zap.Sink, error){
+		"syslog":   newSyslogSink,
+		"unixgram": newUnixgramSink,
+		"rotate":   newTimeRotateSink,
+		"memory":   newRingBufferSink,
+	} {
+		if err := zap.RegisterSink(scheme, factory); err != nil {
+			panic(err)
+		}
+	}
+}
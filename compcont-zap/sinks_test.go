@@ -0,0 +1,217 @@
+package compcontzap
+
+import (
+	"bytes"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRingBufferSink(t *testing.T) {
+	encoding := "json"
+	logger, err := New(Config{
+		BaseConfig:  "production",
+		ExtraConfig: ExtraConfig{Encoding: &encoding, OutputPaths: []string{"memory://test-ring?size=2"}},
+	})
+	assert.NoError(t, err)
+	logger.Info("first")
+	logger.Info("second")
+	logger.Info("third")
+	assert.NoError(t, Close(logger))
+
+	recent := GetRingBuffer("test-ring").Recent(0)
+	assert.Len(t, recent, 2)
+	assert.Contains(t, recent[0], "second")
+	assert.Contains(t, recent[1], "third")
+}
+
+func TestTimeRotateSink(t *testing.T) {
+	dir := t.TempDir()
+	old := filepath.Join(dir, "app-2000-01-01.log")
+	assert.NoError(t, os.WriteFile(old, []byte("old\n"), 0o644))
+
+	encoding := "json"
+	logger, err := New(Config{
+		BaseConfig: "production",
+		ExtraConfig: ExtraConfig{
+			Encoding:    &encoding,
+			OutputPaths: []string{"rotate://absolute-path" + filepath.ToSlash(filepath.Join(dir, "app.log")) + "?interval=daily&max_age=24h"},
+		},
+	})
+	assert.NoError(t, err)
+	logger.Info("rotated")
+	assert.NoError(t, Close(logger))
+
+	out, err := os.ReadFile(filepath.Join(dir, "app-"+time.Now().UTC().Format("2006-01-02")+".log"))
+	assert.NoError(t, err)
+	assert.Contains(t, string(out), "rotated")
+	assert.NoFileExists(t, old)
+}
+
+func TestBufferedSink(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "out.log")
+	encoding := "json"
+	logger, err := New(Config{
+		BaseConfig: "production",
+		ExtraConfig: ExtraConfig{
+			Encoding:    &encoding,
+			OutputPaths: []string{file},
+			Buffer:      &BufferConfig{FlushInterval: time.Hour},
+		},
+	})
+	assert.NoError(t, err)
+	logger.Info("buffered")
+
+	out, err := os.ReadFile(file)
+	assert.NoError(t, err)
+	assert.NotContains(t, string(out), "buffered")
+
+	assert.NoError(t, logger.Sync())
+	out, err = os.ReadFile(file)
+	assert.NoError(t, err)
+	assert.Contains(t, string(out), "buffered")
+
+	logger.Info("flushed on close")
+	assert.NoError(t, Close(logger))
+	out, err = os.ReadFile(file)
+	assert.NoError(t, err)
+	assert.Contains(t, string(out), "flushed on close")
+}
+
+func TestSyslogSinkSeverity(t *testing.T) {
+	// unix socket路径长度有限，不使用t.TempDir
+	dir, err := os.MkdirTemp("", "syslog")
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer os.RemoveAll(dir)
+	socket := filepath.Join(dir, "log.sock")
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: socket, Net: "unixgram"})
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer conn.Close()
+
+	encoding := "console"
+	logger, err := New(Config{
+		BaseConfig: "production",
+		ExtraConfig: ExtraConfig{
+			Encoding:    &encoding,
+			OutputPaths: []string{"syslog://" + socket + "?facility=local0&severity=notice&tag=test"},
+		},
+	})
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer Close(logger)
+
+	read := func() string {
+		buf := make([]byte, 4096)
+		_ = conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+		n, err := conn.Read(buf)
+		assert.NoError(t, err)
+		return string(buf[:n])
+	}
+	logger.Info("info entry")
+	assert.True(t, strings.HasPrefix(read(), "<134>"))
+	logger.Warn("warn entry")
+	assert.True(t, strings.HasPrefix(read(), "<132>"))
+	logger.Error("error entry")
+	assert.True(t, strings.HasPrefix(read(), "<131>"))
+
+	// 不经过core直接写入时使用URL中的severity
+	u, err := url.Parse("syslog://" + socket + "?facility=local0&severity=notice&tag=test")
+	if !assert.NoError(t, err) {
+		return
+	}
+	sink, err := newSyslogSink(u)
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer sink.Close()
+	_, err = sink.Write([]byte("raw entry\n"))
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(read(), "<133>"))
+}
+
+func TestRingBufferSizeMismatch(t *testing.T) {
+	// 未指定size的GetRingBuffer不固定大小
+	GetRingBuffer("test-ring-size")
+
+	encoding := "json"
+	logger, err := New(Config{
+		BaseConfig:  "production",
+		ExtraConfig: ExtraConfig{Encoding: &encoding, OutputPaths: []string{"memory://test-ring-size?size=5"}},
+	})
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.NoError(t, Close(logger))
+
+	for _, path := range []string{"memory://test-ring-size", "memory://test-ring-size?size=5"} {
+		logger, err = New(Config{
+			BaseConfig:  "production",
+			ExtraConfig: ExtraConfig{Encoding: &encoding, OutputPaths: []string{path}},
+		})
+		if assert.NoError(t, err, path) {
+			assert.NoError(t, Close(logger))
+		}
+	}
+
+	_, err = New(Config{
+		BaseConfig:  "production",
+		ExtraConfig: ExtraConfig{Encoding: &encoding, OutputPaths: []string{"memory://test-ring-size?size=10"}},
+	})
+	assert.ErrorContains(t, err, "already exists with size 5")
+}
+
+type countingSyncer struct {
+	mu    sync.Mutex
+	lines int
+}
+
+func (c *countingSyncer) Write(p []byte) (int, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.lines += bytes.Count(p, []byte("\n"))
+	return len(p), nil
+}
+
+func (c *countingSyncer) Sync() error {
+	return nil
+}
+
+func TestBufferedSinkStopWhileWriting(t *testing.T) {
+	out := &countingSyncer{}
+	ws, err := (&BufferConfig{QueueSize: 4}).wrap(out, &DropStats{})
+	if !assert.NoError(t, err) {
+		return
+	}
+	var (
+		wg      sync.WaitGroup
+		written atomic.Int64
+	)
+	for range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range 200 {
+				if _, err := ws.Write([]byte("entry\n")); err == nil {
+					written.Add(1)
+				}
+			}
+		}()
+	}
+	ws.stop()
+	wg.Wait()
+	assert.NoError(t, ws.Sync())
+	assert.EqualValues(t, written.Load(), out.lines)
+}
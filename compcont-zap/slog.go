@@ -0,0 +1,155 @@
+package compcontzap
+
+import (
+	"context"
+	"log/slog"
+	"runtime"
+	"slices"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+type SlogHandlerOptions struct {
+	AddSource bool // 使用slog记录的调用位置作为caller
+}
+
+// 基于*zap.Logger的slog.Handler，slog的group对应zap.Namespace，
+// group在有字段写入时才添加，没有字段的group不输出
+type SlogHandler struct {
+	logger *zap.Logger
+	opts   SlogHandlerOptions
+	groups []string // 尚未添加到logger的group
+}
+
+func NewSlogHandler(l *zap.Logger, opts *SlogHandlerOptions) *SlogHandler {
+	h := &SlogHandler{logger: l.WithOptions(zap.WithCaller(false))}
+	if opts != nil {
+		h.opts = *opts
+	}
+	return h
+}
+
+// NewSlog 创建通过l输出的*slog.Logger
+func NewSlog(l *zap.Logger, opts *SlogHandlerOptions) *slog.Logger {
+	return slog.New(NewSlogHandler(l, opts))
+}
+
+func slogToZapLevel(l slog.Level) zapcore.Level {
+	switch {
+	case l < slog.LevelInfo:
+		return zapcore.DebugLevel
+	case l < slog.LevelWarn:
+		return zapcore.InfoLevel
+	case l < slog.LevelError:
+		return zapcore.WarnLevel
+	default:
+		return zapcore.ErrorLevel
+	}
+}
+
+func (h *SlogHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.logger.Core().Enabled(slogToZapLevel(level))
+}
+
+func (h *SlogHandler) Handle(ctx context.Context, record slog.Record) error {
+	ce := h.logger.Check(slogToZapLevel(record.Level), record.Message)
+	if ce == nil {
+		return nil
+	}
+	if !record.Time.IsZero() {
+		ce.Time = record.Time
+	}
+	if h.opts.AddSource && record.PC != 0 {
+		frame, _ := runtime.CallersFrames([]uintptr{record.PC}).Next()
+		ce.Caller = zapcore.NewEntryCaller(frame.PC, frame.File, frame.Line, true)
+		ce.Caller.Function = frame.Function
+	}
+	var fields []zap.Field
+	record.Attrs(func(attr slog.Attr) bool {
+		fields = appendAttr(fields, attr)
+		return true
+	})
+	ce.Write(h.withGroups(fields)...)
+	return nil
+}
+
+// 字段非空时在前面加上尚未添加的group
+func (h *SlogHandler) withGroups(fields []zap.Field) []zap.Field {
+	if len(fields) == 0 || len(h.groups) == 0 {
+		return fields
+	}
+	out := make([]zap.Field, 0, len(h.groups)+len(fields))
+	for _, g := range h.groups {
+		out = append(out, zap.Namespace(g))
+	}
+	return append(out, fields...)
+}
+
+func (h *SlogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	var fields []zap.Field
+	for _, attr := range attrs {
+		fields = appendAttr(fields, attr)
+	}
+	if len(fields) == 0 {
+		return h
+	}
+	return &SlogHandler{logger: h.logger.With(h.withGroups(fields)...), opts: h.opts}
+}
+
+func (h *SlogHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	return &SlogHandler{logger: h.logger, opts: h.opts, groups: append(slices.Clip(h.groups), name)}
+}
+
+// 按照slog.Handler的约定转换attr：忽略空attr，key为空的group内联
+func appendAttr(fields []zap.Field, attr slog.Attr) []zap.Field {
+	attr.Value = attr.Value.Resolve()
+	if attr.Equal(slog.Attr{}) {
+		return fields
+	}
+	switch v := attr.Value; v.Kind() {
+	case slog.KindGroup:
+		attrs := v.Group()
+		if len(attrs) == 0 {
+			return fields
+		}
+		if attr.Key == "" {
+			for _, a := range attrs {
+				fields = appendAttr(fields, a)
+			}
+			return fields
+		}
+		return append(fields, zap.Object(attr.Key, slogGroup(attrs)))
+	case slog.KindString:
+		return append(fields, zap.String(attr.Key, v.String()))
+	case slog.KindInt64:
+		return append(fields, zap.Int64(attr.Key, v.Int64()))
+	case slog.KindUint64:
+		return append(fields, zap.Uint64(attr.Key, v.Uint64()))
+	case slog.KindFloat64:
+		return append(fields, zap.Float64(attr.Key, v.Float64()))
+	case slog.KindBool:
+		return append(fields, zap.Bool(attr.Key, v.Bool()))
+	case slog.KindDuration:
+		return append(fields, zap.Duration(attr.Key, v.Duration()))
+	case slog.KindTime:
+		return append(fields, zap.Time(attr.Key, v.Time()))
+	default:
+		if err, ok := v.Any().(error); ok {
+			return append(fields, zap.NamedError(attr.Key, err))
+		}
+		return append(fields, zap.Any(attr.Key, v.Any()))
+	}
+}
+
+type slogGroup []slog.Attr
+
+func (g slogGroup) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	for _, f := range appendAttr(nil, slog.Attr{Value: slog.GroupValue(g...)}) {
+		f.AddTo(enc)
+	}
+	return nil
+}
@@ -0,0 +1,132 @@
+package compcontzap
+
+import (
+	"bytes"
+	"errors"
+	"log"
+	"log/slog"
+	"testing"
+
+	"github.com/go-compcont/compcont-core"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+func TestSlogHandler(t *testing.T) {
+	encoding := "json"
+	logger, err := New(Config{
+		BaseConfig:  "production",
+		ExtraConfig: ExtraConfig{Encoding: &encoding, OutputPaths: []string{"memory://test-slog"}},
+	})
+	assert.NoError(t, err)
+	defer Close(logger)
+	GetRingBuffer("test-slog").Reset()
+
+	l := NewSlog(logger, &SlogHandlerOptions{AddSource: true})
+	l.Debug("hidden")
+	l.With("a", 1).WithGroup("req").Warn("slog warn", "path", "/x", slog.Group("user", "id", 2), "err", errors.New("boom"))
+
+	recent := GetRingBuffer("test-slog").Recent(0)
+	assert.Len(t, recent, 1)
+	assert.Contains(t, recent[0], `"level":"warn"`)
+	assert.Contains(t, recent[0], `"msg":"slog warn"`)
+	assert.Contains(t, recent[0], `"a":1,"req":{"path":"/x","user":{"id":2},"err":"boom"}`)
+	assert.Contains(t, recent[0], `"caller":"compcont-zap/slog_test.go:`)
+}
+
+func TestSlogEmptyGroup(t *testing.T) {
+	encoding := "json"
+	logger, err := New(Config{
+		BaseConfig:  "production",
+		ExtraConfig: ExtraConfig{Encoding: &encoding, OutputPaths: []string{"memory://test-slog-group"}},
+	})
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer Close(logger)
+	ring := GetRingBuffer("test-slog-group")
+	ring.Reset()
+
+	l := NewSlog(logger, nil)
+	l.WithGroup("empty").Info("no attrs")
+	l.WithGroup("a").With().WithGroup("b").Info("nested", "k", "v")
+	l.WithGroup("a").With("x", 1).WithGroup("b").Info("partial")
+
+	recent := ring.Recent(0)
+	if !assert.Len(t, recent, 3) {
+		return
+	}
+	assert.NotContains(t, recent[0], `"empty"`)
+	assert.Contains(t, recent[1], `"a":{"b":{"k":"v"}}`)
+	assert.Contains(t, recent[2], `"a":{"x":1}`)
+	assert.NotContains(t, recent[2], `"b"`)
+}
+
+func TestSlogDefaultRestoredOnClose(t *testing.T) {
+	previous := slog.Default()
+	var out bytes.Buffer
+	defer log.SetOutput(log.Writer())
+	log.SetOutput(&out)
+
+	encoding := "json"
+	logger, err := New(Config{
+		BaseConfig:  "production",
+		SlogDefault: true,
+		ExtraConfig: ExtraConfig{Encoding: &encoding, OutputPaths: []string{"memory://test-slog-default"}},
+	})
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.NotSame(t, previous, slog.Default())
+	assert.NoError(t, Close(logger))
+	assert.Same(t, previous, slog.Default())
+
+	log.Print("after close")
+	assert.Contains(t, out.String(), "after close")
+}
+
+func TestSlogComponentDefaultRestoredOnDestroy(t *testing.T) {
+	previous := slog.Default()
+	var out bytes.Buffer
+	defer log.SetOutput(log.Writer())
+	log.SetOutput(&out)
+
+	logger, err := New(Config{BaseConfig: "production", ExtraConfig: ExtraConfig{OutputPaths: []string{"memory://test-slog-component"}}})
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer Close(logger)
+	registry := compcont.NewFactoryRegistry()
+	MustRegisterSlog(registry)
+	compcont.MustRegister(registry, &compcont.TypedSimpleComponentFactory[struct{}, *zap.Logger]{
+		TypeID: "test.logger",
+		CreateInstanceFunc: func(ctx compcont.BuildContext, config struct{}) (*zap.Logger, error) {
+			return logger, nil
+		},
+	})
+	cc := compcont.NewComponentContainer(compcont.WithFactoryRegistry(registry))
+	if !assert.NoError(t, cc.LoadNamedComponents([]compcont.ComponentConfig{
+		{Name: "logger", Type: "test.logger"},
+		{Name: "slog", Type: SlogTypeID, Deps: []compcont.ComponentName{"logger"}, Config: map[string]any{
+			"logger":  map[string]any{"refer": "logger"},
+			"default": true,
+		}},
+	})) {
+		return
+	}
+	c, err := cc.GetComponent("slog")
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Same(t, c.Instance, slog.Default())
+
+	factory, err := registry.GetFactory(SlogTypeID)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.NoError(t, factory.DestroyInstance(c.Context, c.Instance))
+	assert.Same(t, previous, slog.Default())
+
+	log.Print("after destroy")
+	assert.Contains(t, out.String(), "after destroy")
+}
@@ -2,9 +2,14 @@ package compcontzap
 
 import (
 	"fmt"
+	"log"
+	"log/slog"
 	"net/url"
 	"strconv"
 
+	"github.com/go-compcont/compcont-core"
+	"github.com/go-compcont/compcont-std/metrics"
+	"github.com/go-compcont/compcont-std/reloading"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 	"gopkg.in/natefinch/lumberjack.v2"
@@ -13,17 +18,37 @@ import (
 type TimeEncoder string
 
 const (
-	TimeEncoderRFC3339 = "RFC3339Nano"
+	TimeEncoderRFC3339     = "RFC3339Nano"
+	TimeEncoderRFC3339Sec  = "RFC3339"
+	TimeEncoderISO8601     = "ISO8601"
+	TimeEncoderEpoch       = "epoch"        // 浮点秒
+	TimeEncoderEpochMillis = "epoch_millis" // 浮点毫秒
+	TimeEncoderEpochNanos  = "epoch_nanos"  // 整数纳秒
 )
 
 type ExtraConfig struct {
-	Level             *string     `ccf:"level"`
-	DisableCaller     *bool       `ccf:"disable_caller"`
-	DisableStacktrace *bool       `ccf:"disable_stacktrace"`
-	Encoding          *string     `ccf:"encoding"`
-	OutputPaths       []string    `ccf:"output_paths"`
-	ErrorOutputPaths  []string    `ccf:"error_output_paths"`
-	TimeEncoder       TimeEncoder `ccf:"time_encoder"`
+	Level             *string     `ccf:"level" json:"level" yaml:"level"`
+	DisableCaller     *bool       `ccf:"disable_caller" json:"disable_caller" yaml:"disable_caller"`
+	DisableStacktrace *bool       `ccf:"disable_stacktrace" json:"disable_stacktrace" yaml:"disable_stacktrace"`
+	Encoding          *string     `ccf:"encoding" json:"encoding" yaml:"encoding"`
+	OutputPaths       []string    `ccf:"output_paths" json:"output_paths" yaml:"output_paths"`
+	ErrorOutputPaths  []string    `ccf:"error_output_paths" json:"error_output_paths" yaml:"error_output_paths"`
+	TimeEncoder       TimeEncoder `ccf:"time_encoder" json:"time_encoder" yaml:"time_encoder"`
+
+	// 完整的encoder配置，time_encoder仍然有效但以encoder中的配置优先
+	Encoder *EncoderConfig `ccf:"encoder" json:"encoder" yaml:"encoder"`
+
+	// 异步缓冲写入output_paths，不填为同步写入
+	Buffer *BufferConfig `ccf:"buffer" json:"buffer" yaml:"buffer"`
+
+	// 输出前对消息与字段脱敏，对所有core生效
+	Redact *RedactConfig `ccf:"redact" json:"redact" yaml:"redact"`
+
+	// 采样与限流，不填沿用base_config中的采样配置
+	Sampling *SamplingConfig `ccf:"sampling" json:"sampling" yaml:"sampling"`
+
+	// 按logger名称(logger.Named)覆盖级别，名称按"."分段前缀匹配
+	NamedLevels map[string]string `ccf:"named_levels" json:"named_levels" yaml:"named_levels"`
 }
 
 func (c *ExtraConfig) MergeTo(input zap.Config) (output zap.Config, err error) {
@@ -50,24 +75,44 @@ func (c *ExtraConfig) MergeTo(input zap.Config) (output zap.Config, err error) {
 		output.ErrorOutputPaths = c.ErrorOutputPaths
 	}
 
-	switch c.TimeEncoder {
-	case "":
-	case TimeEncoderRFC3339:
-		output.EncoderConfig.EncodeTime = zapcore.RFC3339NanoTimeEncoder
-	default:
-		err = fmt.Errorf("unsupported args: %s", c.TimeEncoder)
+	if c.Sampling != nil {
+		c.Sampling.mergeTo(&output)
+	}
+
+	if err = c.TimeEncoder.mergeTo(&output.EncoderConfig); err != nil {
 		return
 	}
+	if c.Encoder != nil {
+		err = c.Encoder.mergeTo(&output.EncoderConfig)
+	}
 	return
 }
 
 type Config struct {
-	Default     bool        `ccf:"default"`
-	BaseConfig  string      `ccf:"base_config"` // "","development","production"
-	ExtraConfig ExtraConfig `ccf:"extra_config"`
+	Default     bool `ccf:"default" json:"default" yaml:"default"`
+	SlogDefault bool `ccf:"slog_default" json:"slog_default" yaml:"slog_default"` // 同时作为slog的默认输出
+
+	// 多个logger配置default时，default_priority高者成为默认logger，相同时组件绝对路径字典序小者胜出
+	DefaultPriority int         `ccf:"default_priority" json:"default_priority" yaml:"default_priority"`
+	ReplaceGlobals  bool        `ccf:"replace_globals" json:"replace_globals" yaml:"replace_globals"` // 成为默认logger时同时替换zap.L()与zap.S()
+	BaseConfig      string      `ccf:"base_config" json:"base_config" yaml:"base_config"`             // "","development","production"
+	ExtraConfig     ExtraConfig `ccf:"extra_config" json:"extra_config" yaml:"extra_config"`
+
+	// 配置后替代extra_config中的output_paths，每个core使用独立的encoder、级别过滤与sink，通过zapcore.NewTee组合
+	Cores []CoreConfig `ccf:"cores" json:"cores" yaml:"cores"`
+
+	// 配置后base_config与extra_config以reloading加载的配置为准，配置变更时logger自动重建，
+	// 其中disable_caller与disable_stacktrace仅在首次构造时生效
+	Reloading *reloading.ReloadingConfigConfig[Config] `ccf:"reloading" json:"-" yaml:"-"`
+
+	// 达到级别的日志转发给notifier组件，仅通过组件配置生效，重新加载配置时保持不变
+	Alerts []AlertConfig `ccf:"alerts" json:"-" yaml:"-"`
+
+	// 按级别统计日志条数并输出丢弃条数的std.metrics组件，仅通过组件配置生效
+	Metrics *compcont.TypedComponentConfig[any, *metrics.Registry] `ccf:"metrics" json:"-" yaml:"-"`
 }
 
-func New(cfg Config) (c *zap.Logger, err error) {
+func (cfg Config) zapConfig() (finalCfg zap.Config, err error) {
 	var baseCfg zap.Config
 	switch cfg.BaseConfig {
 	case "development":
@@ -76,22 +121,63 @@ func New(cfg Config) (c *zap.Logger, err error) {
 		baseCfg = zap.NewProductionConfig()
 	case "":
 	default:
-		panic("unknown logger base config: " + cfg.BaseConfig)
+		err = fmt.Errorf("unknown logger base config: %q", cfg.BaseConfig)
+		return
 	}
-	finalCfg, err := cfg.ExtraConfig.MergeTo(baseCfg)
+	return cfg.ExtraConfig.MergeTo(baseCfg)
+}
+
+func New(cfg Config) (c *zap.Logger, err error) {
+	return newLogger(cfg, "")
+}
+
+// NewWithCores 与New相同，但额外输出到cores，例如测试中使用的zaptest/observer，
+// cores受logger的级别控制与脱敏处理，重建logger时保留
+func NewWithCores(cfg Config, cores ...zapcore.Core) (*zap.Logger, error) {
+	return newLogger(cfg, "", cores...)
+}
+
+// defaultKey为申请默认logger时的Key
+func newLogger(cfg Config, defaultKey string, attached ...zapcore.Core) (c *zap.Logger, err error) {
+	finalCfg, err := cfg.zapConfig()
 	if err != nil {
 		return
 	}
-	c, err = finalCfg.Build()
+	state, err := newLoggerState(cfg, finalCfg, attached)
 	if err != nil {
 		return
 	}
+	c = zap.New(&loggerCore{state: state}, append(buildOptions(finalCfg), zap.ErrorOutput(&errorOutput{state: state}))...)
 	if cfg.Default {
-		SetDefault(c)
+		release := ClaimDefault(c, DefaultClaim{
+			Priority:       cfg.DefaultPriority,
+			Key:            defaultKey,
+			ReplaceGlobals: cfg.ReplaceGlobals,
+		})
+		state.onClose = append(state.onClose, release)
+	}
+	if cfg.SlogDefault {
+		restore := setSlogDefault(NewSlog(c, &SlogHandlerOptions{AddSource: !finalCfg.DisableCaller}))
+		state.onClose = append(state.onClose, restore)
 	}
 	return
 }
 
+// 设置slog的默认logger，返回的函数在默认logger未被替换时恢复之前的设置。
+// slog.SetDefault会同时重定向标准库log，恢复时一并还原
+func setSlogDefault(l *slog.Logger) (restore func()) {
+	previous, output, flags := slog.Default(), log.Writer(), log.Flags()
+	slog.SetDefault(l)
+	return func() {
+		if slog.Default() != l {
+			return
+		}
+		slog.SetDefault(previous)
+		log.SetOutput(output)
+		log.SetFlags(flags)
+	}
+}
+
 type sinkFunc struct {
 	CloseFunc func() error
 	SyncFunc  func() error
@@ -163,6 +249,8 @@ func init() {
 		sink = &sinkFunc{
 			CloseFunc: l.Close,
 			WriteFunc: l.Write,
+			// lumberjack每次Write直接写入文件，没有需要flush的用户态缓冲，
+			// 也不暴露底层文件，无法fsync
 			SyncFunc: func() error {
 				return nil
 			},
@@ -26,31 +26,35 @@ var importFactory compcont.IComponentFactory = &compcont.TypedSimpleComponentFac
 			compcont.WithParentContainer(ctx.Container),
 			compcont.WithContext(ctx),
 		)
-		var bs []byte
-		bs, err = os.ReadFile(config.FromFile)
+		components, err := LoadFile(config.FromFile)
 		if err != nil {
 			return
 		}
-
-		components := []compcont.ComponentConfig{}
-		switch {
-		case strings.HasSuffix(config.FromFile, ".json"):
-			err = json.Unmarshal(bs, &components)
-			if err != nil {
-				return
-			}
-		case strings.HasSuffix(config.FromFile, ".yml") || strings.HasSuffix(config.FromFile, ".yaml"):
-			err = yaml.Unmarshal(bs, &components)
-			if err != nil {
-				return
-			}
-		default:
-			err = fmt.Errorf("unsupported config file format: %s", config.FromFile)
-			return
-		}
 		err = instance.LoadNamedComponents(components)
 		return
 	},
+	DestroyInstanceFunc: func(ctx compcont.BuildContext, instance compcont.IComponentContainer) (err error) {
+		return DestroyComponents(instance)
+	},
+}
+
+// LoadFile 从json或yaml文件中读取组件配置列表
+func LoadFile(file string) (components []compcont.ComponentConfig, err error) {
+	bs, err := os.ReadFile(file)
+	if err != nil {
+		return
+	}
+
+	components = []compcont.ComponentConfig{}
+	switch {
+	case strings.HasSuffix(file, ".json"):
+		err = json.Unmarshal(bs, &components)
+	case strings.HasSuffix(file, ".yml") || strings.HasSuffix(file, ".yaml"):
+		err = yaml.Unmarshal(bs, &components)
+	default:
+		err = fmt.Errorf("unsupported config file format: %s", file)
+	}
+	return
 }
 
 func MustRegisterContainerImport(r compcont.IFactoryRegistry) {
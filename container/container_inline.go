@@ -19,6 +19,9 @@ var inlineFactory compcont.IComponentFactory = &compcont.TypedSimpleComponentFac
 		err = instance.LoadNamedComponents(config.Components)
 		return
 	},
+	DestroyInstanceFunc: func(ctx compcont.BuildContext, instance compcont.IComponentContainer) (err error) {
+		return DestroyComponents(instance)
+	},
 }
 
 func MustRegisterContainerInline(r compcont.IFactoryRegistry) {
@@ -104,3 +104,51 @@ func TestFinder(t *testing.T) {
 	err = cc.LoadNamedComponents(cfg)
 	assert.NoError(t, err)
 }
+
+const destroyYaml = `
+- { name: a, type: closer }
+- { name: b, type: closer, deps: [a] }
+- { name: r, deps: [a], refer: a }
+- name: c
+  type: std.container-inline
+  deps: [b]
+  config:
+    components:
+      - { name: x, type: closer }
+      - { name: y, type: closer, deps: [x] }
+      - { name: z, refer: ../a }
+- { name: d, type: closer, deps: [r] }
+`
+
+func TestDestroyComponents(t *testing.T) {
+	var destroyed []string
+	r := compcont.NewFactoryRegistry()
+	MustRegisterContainerInline(r)
+	compcont.MustRegister(r, &compcont.TypedSimpleComponentFactory[any, string]{
+		TypeID: "closer",
+		CreateInstanceFunc: func(ctx compcont.BuildContext, config any) (instance string, err error) {
+			instance = fmt.Sprint(ctx.GetAbsolutePath())
+			return
+		},
+		DestroyInstanceFunc: func(ctx compcont.BuildContext, instance string) (err error) {
+			destroyed = append(destroyed, instance)
+			return
+		},
+	})
+
+	cfg := []compcont.ComponentConfig{}
+	err := yaml.Unmarshal([]byte(destroyYaml), &cfg)
+	if !assert.NoError(t, err) {
+		return
+	}
+	cc := compcont.NewComponentContainer(compcont.WithFactoryRegistry(r))
+	err = cc.LoadNamedComponents(cfg)
+	if !assert.NoError(t, err) {
+		return
+	}
+
+	err = DestroyComponents(cc)
+	assert.NoError(t, err)
+	// 依赖方先于被依赖方销毁，子容器中的组件随容器销毁，引用组件不会重复销毁
+	assert.Equal(t, []string{"[d]", "[c y]", "[c x]", "[b]", "[a]"}, destroyed)
+}
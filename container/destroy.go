@@ -0,0 +1,69 @@
+package container
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/go-compcont/compcont-core"
+)
+
+// DestroyComponents 按依赖的逆序销毁容器中已加载的具名组件，子容器组件会递归销毁其中的组件。
+// compcont-core的UnloadNamedComponents尚未实现，容器关闭时使用该方法代替
+func DestroyComponents(cc compcont.IComponentContainer) error {
+	names := cc.LoadedComponentNames()
+	slices.Sort(names)
+	loaded := map[compcont.ComponentName]compcont.Component{}
+	for _, name := range names {
+		if comp, err := cc.GetComponent(name); err == nil {
+			loaded[name] = comp
+		}
+	}
+
+	// 依赖在前的构造顺序，引用组件保存的是被引用组件，由被引用组件所在的容器负责销毁
+	var order []compcont.ComponentName
+	visited := map[compcont.ComponentName]bool{}
+	var visit func(name compcont.ComponentName)
+	visit = func(name compcont.ComponentName) {
+		if visited[name] {
+			return
+		}
+		visited[name] = true
+		comp, ok := loaded[name]
+		if !ok || comp.Context.Container != cc {
+			return
+		}
+		if target := comp.Context.Config.Name; target != name { // 同一容器内的引用，依赖关系传递到被引用组件
+			visit(target)
+			return
+		}
+		for _, dep := range comp.Context.Config.Deps {
+			visit(dep)
+		}
+		order = append(order, name)
+	}
+	for _, name := range names {
+		visit(name)
+	}
+
+	var errs []error
+	for i := len(order) - 1; i >= 0; i-- {
+		if err := destroyComponent(cc, loaded[order[i]]); err != nil {
+			errs = append(errs, fmt.Errorf("destroy %s: %w", order[i], err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func destroyComponent(cc compcont.IComponentContainer, comp compcont.Component) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+	factory, err := cc.FactoryRegistry().GetFactory(comp.Context.Config.Type)
+	if err != nil {
+		return
+	}
+	return factory.DestroyInstance(comp.Context, comp.Instance)
+}
@@ -0,0 +1,52 @@
+package container
+
+import (
+	"reflect"
+	"time"
+
+	"github.com/go-compcont/compcont-core"
+	"github.com/go-compcont/compcont-std/schema"
+)
+
+// BuildObserver 具名组件构造结束后回调，d为CreateInstance的耗时，err为构造错误
+type BuildObserver func(ctx compcont.BuildContext, d time.Duration, err error)
+
+// ObserveBuild 包装工厂注册器，由其创建的具名组件(包括嵌套容器中的组件)构造结束后都会回调observe，
+// 匿名组件没有路径，不回调
+func ObserveBuild(inner compcont.IFactoryRegistry, observe BuildObserver) compcont.IFactoryRegistry {
+	return &observedRegistry{IFactoryRegistry: inner, observe: observe}
+}
+
+type observedRegistry struct {
+	compcont.IFactoryRegistry
+	observe BuildObserver
+}
+
+func (r *observedRegistry) GetFactory(t compcont.ComponentTypeID) (f compcont.IComponentFactory, err error) {
+	f, err = r.IFactoryRegistry.GetFactory(t)
+	if err != nil {
+		return
+	}
+	f = &observedFactory{IComponentFactory: f, observe: r.observe}
+	return
+}
+
+type observedFactory struct {
+	compcont.IComponentFactory
+	observe BuildObserver
+}
+
+// 包装后仍能推断被包装工厂的配置类型
+func (f *observedFactory) ConfigType() reflect.Type {
+	t, _ := schema.ConfigTypeOf(f.IComponentFactory)
+	return t
+}
+
+func (f *observedFactory) CreateInstance(ctx compcont.BuildContext, config any) (instance any, err error) {
+	start := time.Now()
+	instance, err = f.IComponentFactory.CreateInstance(ctx, config)
+	if ctx.Config.Name != "" {
+		f.observe(ctx, time.Since(start), err)
+	}
+	return
+}
@@ -0,0 +1,16 @@
+package container
+
+import (
+	"strings"
+
+	"github.com/go-compcont/compcont-core"
+)
+
+// Path 组件绝对路径的字符串形式，例如"/a/b"，用作日志字段、指标标签与依赖图的节点ID
+func Path(path []compcont.ComponentName) string {
+	parts := make([]string, 0, len(path))
+	for _, p := range path {
+		parts = append(parts, string(p))
+	}
+	return "/" + strings.Join(parts, "/")
+}
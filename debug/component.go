@@ -18,12 +18,7 @@ type Config struct {
 var factory compcont.IComponentFactory = &compcont.TypedSimpleComponentFactory[Config, any]{
 	TypeID: TypeID,
 	CreateInstanceFunc: func(ctx compcont.BuildContext, cfg Config) (instance any, err error) {
-		var logger *zap.Logger
-		if cfg.Logger != nil {
-			logger = cfg.Logger.MustLoadComponent(ctx.Container).Instance
-		} else {
-			logger = compcontzap.GetDefault()
-		}
+		logger := compcontzap.ComponentLogger(ctx, cfg.Logger)
 
 		var level zapcore.Level
 		if cfg.Level != "" {
@@ -37,6 +32,7 @@ var factory compcont.IComponentFactory = &compcont.TypedSimpleComponentFactory[C
 			level = zapcore.DebugLevel
 		}
 
+		// 保留name/type/absolute_path，与ComponentLogger附带的component/component_type并存
 		logger.Log(
 			level,
 			"compcont debug info",
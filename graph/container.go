@@ -0,0 +1,179 @@
+package graph
+
+import (
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/go-compcont/compcont-core"
+	"github.com/go-compcont/compcont-std/container"
+	"github.com/go-compcont/compcont-std/schema"
+	"github.com/go-compcont/compcont-std/validate"
+)
+
+type options struct {
+	timings *Timings
+}
+
+type Option func(o *options)
+
+// WithTimings 在节点上标注组件的构造耗时
+func WithTimings(t *Timings) Option {
+	return func(o *options) {
+		o.timings = t
+	}
+}
+
+// FromContainer 遍历已构造完成的容器(包括嵌套的容器)构建组件图
+func FromContainer(cc compcont.IComponentContainer, optFns ...Option) *Graph {
+	var opt options
+	for _, fn := range optFns {
+		fn(&opt)
+	}
+
+	g := &Graph{}
+	var walk func(c compcont.IComponentContainer, path []compcont.ComponentName)
+	walk = func(c compcont.IComponentContainer, path []compcont.ComponentName) {
+		names := c.LoadedComponentNames()
+		slices.Sort(names)
+		for _, name := range names {
+			comp, err := c.GetComponent(name)
+			if err != nil {
+				continue
+			}
+			nodePath := append(slices.Clone(path), name)
+			id := container.Path(nodePath)
+			node := &Node{ID: id, Name: name}
+			if len(path) > 0 {
+				node.Parent = container.Path(path)
+			}
+			g.Nodes = append(g.Nodes, node)
+
+			// 引用组件在容器中保存的是被引用组件本身，其上下文属于被引用的组件
+			if comp.Context.Container != c || comp.Context.Config.Name != name {
+				target := container.Path(comp.Context.GetAbsolutePath())
+				node.Refer = target
+				g.Edges = append(g.Edges, &Edge{From: id, To: target, Kind: EdgeKindRefer})
+				continue
+			}
+
+			node.Type = comp.Context.Config.Type
+			if opt.timings != nil {
+				node.BuildDuration, _ = opt.timings.Get(nodePath)
+			}
+			for _, dep := range comp.Context.Config.Deps {
+				g.Edges = append(g.Edges, &Edge{From: id, To: container.Path(append(slices.Clone(path), dep)), Kind: EdgeKindDep})
+			}
+			for _, ref := range configRefs(c, comp.Context.Config) {
+				if target, ok := findRefer(c, ref.refer); ok {
+					g.Edges = append(g.Edges, &Edge{From: id, To: container.Path(target), Kind: EdgeKindRef, Label: ref.field})
+				}
+			}
+			if child, ok := comp.Instance.(compcont.IComponentContainer); ok {
+				node.IsContainer = true
+				walk(child, nodePath)
+			}
+		}
+	}
+	walk(cc, nil)
+	return g
+}
+
+type configRef struct {
+	field string
+	refer string
+}
+
+// 解码组件配置，收集其中通过refer引用的其他组件
+func configRefs(c compcont.IComponentContainer, cfg compcont.ComponentConfig) (refs []configRef) {
+	factory, err := c.FactoryRegistry().GetFactory(cfg.Type)
+	if err != nil {
+		return
+	}
+	t, ok := schema.ConfigTypeOf(factory)
+	if !ok {
+		return
+	}
+	decoded, err := validate.DecodeConfig(t, cfg.Config)
+	if err != nil {
+		return
+	}
+	var walk func(v reflect.Value, field string)
+	walk = func(v reflect.Value, field string) {
+		if !v.IsValid() {
+			return
+		}
+		for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+			if v.IsNil() {
+				return
+			}
+			v = v.Elem()
+		}
+		if ok, _ := schema.IsComponentConfig(v.Type()); ok {
+			if v.FieldByName("Type").String() == "" {
+				if refer := v.FieldByName("Refer").String(); refer != "" {
+					refs = append(refs, configRef{field: field, refer: refer})
+				}
+			}
+			return
+		}
+		switch v.Kind() {
+		case reflect.Struct:
+			for i := range v.NumField() {
+				key, _, skip := schema.FieldKey(v.Type().Field(i))
+				if !skip {
+					walk(v.Field(i), field+"."+key)
+				}
+			}
+		case reflect.Slice, reflect.Array:
+			for i := range v.Len() {
+				walk(v.Index(i), field+"["+strconv.Itoa(i)+"]")
+			}
+		case reflect.Map:
+			iter := v.MapRange()
+			for iter.Next() {
+				walk(iter.Value(), field+"."+iter.Key().String())
+			}
+		}
+	}
+	if cfg.Type != container.InlineContainerType { // 内联容器的子组件单独遍历
+		walk(reflect.ValueOf(decoded), "config")
+	}
+	return
+}
+
+// 按照容器的查找规则定位refer指向的组件，返回其绝对路径
+func findRefer(c compcont.IComponentContainer, refer string) (target []compcont.ComponentName, ok bool) {
+	parts := strings.Split(refer, "/")
+	if parts[0] == "" {
+		for c.GetParent() != nil {
+			c = c.GetParent()
+		}
+		parts = parts[1:]
+	}
+	for i, p := range parts {
+		switch p {
+		case ".":
+			continue
+		case "..":
+			if c = c.GetParent(); c == nil {
+				return
+			}
+			continue
+		}
+		comp, err := c.GetComponent(compcont.ComponentName(p))
+		if err != nil {
+			return
+		}
+		if i == len(parts)-1 {
+			return comp.Context.GetAbsolutePath(), true
+		}
+		child, isContainer := comp.Instance.(compcont.IComponentContainer)
+		if !isContainer {
+			return
+		}
+		c = child
+	}
+	return
+}
@@ -0,0 +1,142 @@
+package graph
+
+import (
+	"strings"
+	"time"
+
+	"github.com/go-compcont/compcont-core"
+	"github.com/go-compcont/compcont-std/container"
+	"github.com/go-compcont/compcont-std/validate"
+)
+
+type EdgeKind string
+
+const (
+	EdgeKindDep   EdgeKind = "dep"   // deps声明的依赖关系
+	EdgeKindRefer EdgeKind = "refer" // 引用组件指向被引用的组件
+	EdgeKindRef   EdgeKind = "ref"   // 组件配置中通过refer引用了其他组件
+)
+
+// 组件图中的一个组件
+type Node struct {
+	ID          string                   `json:"id"` // 组件的绝对路径
+	Name        compcont.ComponentName   `json:"name"`
+	Type        compcont.ComponentTypeID `json:"type,omitempty"`
+	Refer       string                   `json:"refer,omitempty"`
+	Parent      string                   `json:"parent,omitempty"` // 所在容器组件的ID，根容器为空
+	IsContainer bool                     `json:"is_container,omitempty"`
+
+	BuildDuration time.Duration `json:"build_duration_ns,omitempty"` // 构造耗时，嵌套容器包含其子组件的耗时
+}
+
+type Edge struct {
+	From  string   `json:"from"`
+	To    string   `json:"to"`
+	Kind  EdgeKind `json:"kind"`
+	Label string   `json:"label,omitempty"`
+}
+
+// 组件图，节点按照容器的深度优先顺序排列
+type Graph struct {
+	Nodes []*Node `json:"nodes"`
+	Edges []*Edge `json:"edges"`
+}
+
+func (g *Graph) Node(id string) *Node {
+	for _, n := range g.Nodes {
+		if n.ID == id {
+			return n
+		}
+	}
+	return nil
+}
+
+// 直接位于容器parent中的节点，parent为空表示根容器
+func (g *Graph) Children(parent string) (nodes []*Node) {
+	for _, n := range g.Nodes {
+		if n.Parent == parent {
+			nodes = append(nodes, n)
+		}
+	}
+	return
+}
+
+func parentID(id string) string {
+	i := strings.LastIndex(id, "/")
+	if i <= 0 {
+		return ""
+	}
+	return id[:i]
+}
+
+// FromValidated 基于静态校验得到的容器树构建组件图。
+// 组件配置中嵌套的匿名内联容器的ID为<所属组件ID>/<字段路径>，与所属组件位于同一容器中
+func FromValidated(root *validate.Container) *Graph {
+	g := &Graph{}
+	ids := map[*validate.Component]string{}
+	idOf := func(comp *validate.Component) string {
+		if id, ok := ids[comp]; ok {
+			return id
+		}
+		return container.Path(comp.AbsolutePath())
+	}
+	var assign func(c *validate.Container, prefix string)
+	assign = func(c *validate.Container, prefix string) {
+		for _, comp := range c.Components {
+			id := prefix + "/" + string(comp.Config.Name)
+			ids[comp] = id
+			if comp.Children != nil {
+				assign(comp.Children, id)
+			}
+			for _, anonymous := range comp.Anonymous {
+				ids[anonymous] = id + "/" + anonymous.Field
+				assign(anonymous.Children, ids[anonymous])
+			}
+		}
+	}
+	assign(root, "")
+
+	var walk func(c *validate.Container, parent string)
+	walk = func(c *validate.Container, parent string) {
+		for _, comp := range c.Components {
+			id := ids[comp]
+			g.Nodes = append(g.Nodes, &Node{
+				ID:          id,
+				Name:        comp.Config.Name,
+				Type:        comp.Config.Type,
+				Refer:       comp.Config.Refer,
+				Parent:      parent,
+				IsContainer: comp.Children != nil,
+			})
+			for _, dep := range comp.Config.Deps {
+				if target, ok := c.Get(dep); ok {
+					g.Edges = append(g.Edges, &Edge{From: id, To: idOf(target), Kind: EdgeKindDep})
+				}
+			}
+			if comp.Target != nil {
+				g.Edges = append(g.Edges, &Edge{From: id, To: idOf(comp.Target), Kind: EdgeKindRefer})
+			}
+			for _, ref := range comp.Refs {
+				if ref.Target != nil {
+					g.Edges = append(g.Edges, &Edge{From: id, To: idOf(ref.Target), Kind: EdgeKindRef, Label: ref.Field})
+				}
+			}
+			if comp.Children != nil {
+				walk(comp.Children, id)
+			}
+			for _, anonymous := range comp.Anonymous {
+				anonymousID := ids[anonymous]
+				g.Nodes = append(g.Nodes, &Node{
+					ID:          anonymousID,
+					Type:        anonymous.Config.Type,
+					Parent:      parent,
+					IsContainer: true,
+				})
+				g.Edges = append(g.Edges, &Edge{From: id, To: anonymousID, Kind: EdgeKindRef, Label: anonymous.Field})
+				walk(anonymous.Children, anonymousID)
+			}
+		}
+	}
+	walk(root, "")
+	return g
+}
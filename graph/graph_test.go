@@ -0,0 +1,117 @@
+package graph
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/go-compcont/compcont-core"
+	"github.com/go-compcont/compcont-std/container"
+	"github.com/go-compcont/compcont-std/validate"
+	"github.com/stretchr/testify/assert"
+	"gopkg.in/yaml.v3"
+)
+
+type echoConfig struct {
+	Message string                                   `ccf:"message"`
+	Next    *compcont.TypedComponentConfig[any, any] `ccf:"next"`
+}
+
+var echoFactory = &compcont.TypedSimpleComponentFactory[echoConfig, any]{
+	TypeID: "echo",
+	CreateInstanceFunc: func(ctx compcont.BuildContext, config echoConfig) (instance any, err error) {
+		instance = config.Message
+		return
+	},
+}
+
+const cfgYaml = `
+- name: a
+  type: echo
+  config: { message: "a" }
+- { name: b, deps: [a], refer: a }
+- name: c
+  type: std.container-inline
+  deps: [b]
+  config:
+    components:
+      - name: d
+        type: echo
+        config: { next: { refer: "../b" } }
+`
+
+func TestFromContainer(t *testing.T) {
+	registry := compcont.NewFactoryRegistry()
+	compcont.MustRegister(registry, echoFactory)
+	container.MustRegisterContainerInline(registry)
+
+	timings := NewTimings()
+	cc := compcont.NewComponentContainer(compcont.WithFactoryRegistry(timings.Registry(registry)))
+	var cfg []compcont.ComponentConfig
+	assert.NoError(t, yaml.Unmarshal([]byte(cfgYaml), &cfg))
+	assert.NoError(t, cc.LoadNamedComponents(cfg))
+
+	g := FromContainer(cc, WithTimings(timings))
+	assert.Len(t, g.Nodes, 4)
+	assert.Equal(t, "/a", g.Node("/b").Refer)
+	assert.True(t, g.Node("/c").IsContainer)
+	assert.Equal(t, "/c", g.Node("/c/d").Parent)
+	assert.NotZero(t, g.Node("/c/d").BuildDuration)
+	assert.Contains(t, g.Edges, &Edge{From: "/c/d", To: "/a", Kind: EdgeKindRef, Label: "config.next"})
+	assert.Contains(t, g.Edges, &Edge{From: "/c", To: "/b", Kind: EdgeKindDep})
+
+	var buf bytes.Buffer
+	assert.NoError(t, WriteDOT(&buf, g))
+	assert.Contains(t, buf.String(), `subgraph "cluster_/c"`)
+	buf.Reset()
+	assert.NoError(t, WriteMermaid(&buf, g))
+	assert.Contains(t, buf.String(), "-.->|refer|")
+}
+
+type holderConfig struct {
+	Inner *compcont.TypedComponentConfig[any, any] `ccf:"inner"`
+}
+
+const anonymousYaml = `
+- name: a
+  type: echo
+  config: { message: "a" }
+- name: h
+  type: holder
+  deps: [a]
+  config:
+    inner:
+      type: std.container-inline
+      config:
+        components:
+          - name: x
+            type: echo
+            config: { next: { refer: "../a" } }
+`
+
+func TestFromValidatedAnonymous(t *testing.T) {
+	registry := compcont.NewFactoryRegistry()
+	compcont.MustRegister(registry, echoFactory)
+	compcont.MustRegister(registry, &compcont.TypedSimpleComponentFactory[holderConfig, any]{TypeID: "holder"})
+	container.MustRegisterContainerInline(registry)
+
+	root, err := validate.New(registry).ValidateBytes("anonymous.yaml", []byte(anonymousYaml))
+	if !assert.NoError(t, err) {
+		return
+	}
+	g := FromValidated(root)
+	assert.Len(t, g.Nodes, 4)
+	inner := g.Node("/h/config.inner")
+	if assert.NotNil(t, inner) {
+		assert.True(t, inner.IsContainer)
+		assert.Equal(t, "", inner.Parent)
+	}
+	if x := g.Node("/h/config.inner/x"); assert.NotNil(t, x) {
+		assert.Equal(t, "/h/config.inner", x.Parent)
+	}
+	assert.Contains(t, g.Edges, &Edge{From: "/h", To: "/h/config.inner", Kind: EdgeKindRef, Label: "config.inner"})
+	assert.Contains(t, g.Edges, &Edge{From: "/h/config.inner/x", To: "/a", Kind: EdgeKindRef, Label: "config.next"})
+
+	var buf bytes.Buffer
+	assert.NoError(t, WriteDOT(&buf, g))
+	assert.Contains(t, buf.String(), `subgraph "cluster_/h/config.inner"`)
+}
@@ -0,0 +1,128 @@
+package graph
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+func nodeLabel(n *Node) (label string) {
+	switch {
+	case n.Type != "":
+		label = fmt.Sprintf("%s\n[%s]", n.Name, n.Type)
+	case n.Refer != "":
+		label = fmt.Sprintf("%s\n-> %s", n.Name, n.Refer)
+	default:
+		label = string(n.Name)
+	}
+	if n.BuildDuration > 0 {
+		label += "\n" + n.BuildDuration.Round(time.Microsecond).String()
+	}
+	return
+}
+
+func dotQuote(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, `"`, `\"`)
+	s = strings.ReplaceAll(s, "\n", `\n`)
+	return `"` + s + `"`
+}
+
+// WriteDOT 以Graphviz DOT格式输出组件图，嵌套容器输出为cluster子图
+func WriteDOT(w io.Writer, g *Graph) error {
+	bw := bufio.NewWriter(w)
+	fmt.Fprintln(bw, "digraph compcont {")
+	fmt.Fprintln(bw, "  rankdir=LR;")
+	fmt.Fprintln(bw, "  node [shape=box];")
+
+	var writeNodes func(parent, indent string)
+	writeNodes = func(parent, indent string) {
+		for _, n := range g.Children(parent) {
+			attrs := "label=" + dotQuote(nodeLabel(n))
+			if n.Type == "" {
+				attrs += ", style=dashed"
+			}
+			fmt.Fprintf(bw, "%s%s [%s];\n", indent, dotQuote(n.ID), attrs)
+			if n.IsContainer {
+				fmt.Fprintf(bw, "%ssubgraph %s {\n", indent, dotQuote("cluster_"+n.ID))
+				fmt.Fprintf(bw, "%s  label=%s;\n", indent, dotQuote(string(n.Name)))
+				writeNodes(n.ID, indent+"  ")
+				fmt.Fprintf(bw, "%s}\n", indent)
+			}
+		}
+	}
+	writeNodes("", "  ")
+
+	for _, e := range g.Edges {
+		var attrs []string
+		switch e.Kind {
+		case EdgeKindRefer:
+			attrs = append(attrs, "style=dashed", "label="+dotQuote("refer"))
+		case EdgeKindRef:
+			attrs = append(attrs, "style=dotted", "label="+dotQuote(e.Label))
+		}
+		fmt.Fprintf(bw, "  %s -> %s", dotQuote(e.From), dotQuote(e.To))
+		if len(attrs) > 0 {
+			fmt.Fprintf(bw, " [%s]", strings.Join(attrs, ", "))
+		}
+		fmt.Fprintln(bw, ";")
+	}
+	fmt.Fprintln(bw, "}")
+	return bw.Flush()
+}
+
+func mermaidQuote(s string) string {
+	s = strings.ReplaceAll(s, `"`, "#quot;")
+	s = strings.ReplaceAll(s, "\n", "<br/>")
+	return `"` + s + `"`
+}
+
+// WriteMermaid 以Mermaid flowchart格式输出组件图，嵌套容器输出为subgraph
+func WriteMermaid(w io.Writer, g *Graph) error {
+	ids := map[string]string{}
+	for i, n := range g.Nodes {
+		ids[n.ID] = "n" + strconv.Itoa(i)
+	}
+
+	bw := bufio.NewWriter(w)
+	fmt.Fprintln(bw, "flowchart LR")
+	var writeNodes func(parent, indent string)
+	writeNodes = func(parent, indent string) {
+		for _, n := range g.Children(parent) {
+			fmt.Fprintf(bw, "%s%s[%s]\n", indent, ids[n.ID], mermaidQuote(nodeLabel(n)))
+			if n.IsContainer {
+				fmt.Fprintf(bw, "%ssubgraph s%s[%s]\n", indent, ids[n.ID], mermaidQuote(string(n.Name)))
+				writeNodes(n.ID, indent+"  ")
+				fmt.Fprintf(bw, "%send\n", indent)
+			}
+		}
+	}
+	writeNodes("", "  ")
+
+	for _, e := range g.Edges {
+		from, to := ids[e.From], ids[e.To]
+		if from == "" || to == "" {
+			continue
+		}
+		switch e.Kind {
+		case EdgeKindRefer:
+			fmt.Fprintf(bw, "  %s -.->|refer| %s\n", from, to)
+		case EdgeKindRef:
+			fmt.Fprintf(bw, "  %s -.->|%s| %s\n", from, mermaidQuote(e.Label), to)
+		default:
+			fmt.Fprintf(bw, "  %s --> %s\n", from, to)
+		}
+	}
+	return bw.Flush()
+}
+
+// WriteJSON 以JSON格式输出组件图
+func WriteJSON(w io.Writer, g *Graph) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(g)
+}
@@ -0,0 +1,39 @@
+package graph
+
+import (
+	"sync"
+	"time"
+
+	"github.com/go-compcont/compcont-core"
+	"github.com/go-compcont/compcont-std/container"
+)
+
+// 记录组件的构造耗时，通过Registry包装容器使用的工厂注册器
+type Timings struct {
+	mu        sync.RWMutex
+	durations map[string]time.Duration
+}
+
+func NewTimings() *Timings {
+	return &Timings{durations: map[string]time.Duration{}}
+}
+
+func (t *Timings) Get(path []compcont.ComponentName) (d time.Duration, ok bool) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+	d, ok = t.durations[container.Path(path)]
+	return
+}
+
+func (t *Timings) record(path []compcont.ComponentName, d time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.durations[container.Path(path)] = d
+}
+
+// Registry 包装工厂注册器，由其创建的组件(包括嵌套容器中的组件)都会记录构造耗时
+func (t *Timings) Registry(inner compcont.IFactoryRegistry) compcont.IFactoryRegistry {
+	return container.ObserveBuild(inner, func(ctx compcont.BuildContext, d time.Duration, err error) {
+		t.record(ctx.GetAbsolutePath(), d)
+	})
+}
@@ -0,0 +1,209 @@
+package httpmiddleware
+
+import (
+	"compress/gzip"
+	"context"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/go-compcont/compcont-core"
+	compcontzap "github.com/go-compcont/compcont-std/compcont-zap"
+	httprouter "github.com/go-compcont/compcont-std/http-router"
+	"github.com/go-compcont/compcont-std/reloading"
+	"go.uber.org/zap"
+)
+
+const (
+	RecoveryTypeID  compcont.ComponentTypeID = "std.http.middleware.recovery"
+	CORSTypeID      compcont.ComponentTypeID = "std.http.middleware.cors"
+	BodyLimitTypeID compcont.ComponentTypeID = "std.http.middleware.body-limit"
+	TimeoutTypeID   compcont.ComponentTypeID = "std.http.middleware.timeout"
+	GzipTypeID      compcont.ComponentTypeID = "std.http.middleware.gzip"
+	AuthTypeID      compcont.ComponentTypeID = "std.http.middleware.auth"
+	IPAllowTypeID   compcont.ComponentTypeID = "std.http.middleware.ip-allow"
+	ChainTypeID     compcont.ComponentTypeID = "std.http.middleware.chain"
+)
+
+type RecoveryConfig struct {
+	Logger     *compcont.TypedComponentConfig[any, *zap.Logger] `ccf:"logger"`     // 不填使用默认logger
+	Stacktrace bool                                             `ccf:"stacktrace"` // 日志中输出panic的堆栈
+}
+
+var recoveryFactory compcont.IComponentFactory = &compcont.TypedSimpleComponentFactory[RecoveryConfig, Middleware]{
+	TypeID: RecoveryTypeID,
+	CreateInstanceFunc: func(ctx compcont.BuildContext, cfg RecoveryConfig) (instance Middleware, err error) {
+		instance = Recovery(compcontzap.ComponentLogger(ctx, cfg.Logger), cfg.Stacktrace)
+		return
+	},
+}
+
+var corsFactory compcont.IComponentFactory = &compcont.TypedSimpleComponentFactory[CORSConfig, Middleware]{
+	TypeID: CORSTypeID,
+	CreateInstanceFunc: func(ctx compcont.BuildContext, cfg CORSConfig) (instance Middleware, err error) {
+		instance = CORS(cfg)
+		return
+	},
+}
+
+type BodyLimitConfig struct {
+	MaxBytes int64 `ccf:"max_bytes"`
+}
+
+var bodyLimitFactory compcont.IComponentFactory = &compcont.TypedSimpleComponentFactory[BodyLimitConfig, Middleware]{
+	TypeID: BodyLimitTypeID,
+	CreateInstanceFunc: func(ctx compcont.BuildContext, cfg BodyLimitConfig) (instance Middleware, err error) {
+		if cfg.MaxBytes <= 0 {
+			err = fmt.Errorf("max_bytes must be greater than 0")
+			return
+		}
+		instance = BodyLimit(cfg.MaxBytes)
+		return
+	},
+}
+
+type TimeoutConfig struct {
+	Timeout time.Duration `ccf:"timeout"`
+	Message string        `ccf:"message"` // 超时的响应内容
+}
+
+var timeoutFactory compcont.IComponentFactory = &compcont.TypedSimpleComponentFactory[TimeoutConfig, Middleware]{
+	TypeID: TimeoutTypeID,
+	CreateInstanceFunc: func(ctx compcont.BuildContext, cfg TimeoutConfig) (instance Middleware, err error) {
+		if cfg.Timeout <= 0 {
+			err = fmt.Errorf("timeout must be greater than 0")
+			return
+		}
+		instance = Timeout(cfg.Timeout, cfg.Message)
+		return
+	},
+}
+
+type GzipConfig struct {
+	Level *int `ccf:"level"` // 1-9，不填为默认压缩级别
+}
+
+var gzipFactory compcont.IComponentFactory = &compcont.TypedSimpleComponentFactory[GzipConfig, Middleware]{
+	TypeID: GzipTypeID,
+	CreateInstanceFunc: func(ctx compcont.BuildContext, cfg GzipConfig) (instance Middleware, err error) {
+		level := gzip.DefaultCompression
+		if cfg.Level != nil {
+			level = *cfg.Level
+		}
+		return Gzip(level)
+	},
+}
+
+type AuthConfig struct {
+	Credentials *reloading.ReloadingConfigConfig[Credentials] `ccf:"credentials"`
+	Realm       string                                        `ccf:"realm"`
+}
+
+var authFactory compcont.IComponentFactory = &compcont.TypedSimpleComponentFactory[AuthConfig, Middleware]{
+	TypeID: AuthTypeID,
+	CreateInstanceFunc: func(ctx compcont.BuildContext, cfg AuthConfig) (instance Middleware, err error) {
+		if cfg.Credentials == nil {
+			err = fmt.Errorf("credentials is required")
+			return
+		}
+		rc, err := cfg.Credentials.Build(ctx.Container)
+		if err != nil {
+			return
+		}
+		creds, err := rc.LoadConfig(context.Background())
+		if err != nil {
+			_ = rc.Close()
+			return
+		}
+		store := &CredentialsStore{}
+		store.Store(creds)
+		id := rc.AddOnReloadingConfigListener(reloading.OnReloadingConfigListenerFunc[Credentials](func(ctx context.Context, cfg Credentials) error {
+			store.Store(cfg)
+			return nil
+		}))
+		destroy := func() {
+			rc.RemoveOnReloadingConfigListener(id)
+			_ = rc.Close()
+		}
+		if ctx.Config.Name != "" { // 匿名组件的生命周期不由容器管理，不会被销毁
+			authDestroys.Store(authKey{container: ctx.Container, name: ctx.Config.Name}, destroy)
+		}
+		instance = Auth(store, cfg.Realm)
+		return
+	},
+	DestroyInstanceFunc: func(ctx compcont.BuildContext, instance Middleware) (err error) {
+		if destroy, ok := authDestroys.LoadAndDelete(authKey{container: ctx.Container, name: ctx.Config.Name}); ok {
+			destroy.(func())()
+		}
+		return
+	},
+}
+
+type authKey struct {
+	container compcont.IComponentContainer
+	name      compcont.ComponentName
+}
+
+// 具名auth组件到其清理函数的映射，销毁时取消凭据变更的监听并关闭内联的Reloading
+var authDestroys sync.Map
+
+type IPAllowConfig struct {
+	Allow             []string `ccf:"allow"`               // CIDR或IP，例如["10.0.0.0/8", "127.0.0.1"]
+	TrustForwardedFor bool     `ccf:"trust_forwarded_for"` // 使用X-Forwarded-For中最右侧不属于可信代理的地址
+	TrustedProxies    []string `ccf:"trusted_proxies"`     // 可信代理的CIDR或IP，不填时只信任直接连接的对端
+}
+
+var ipAllowFactory compcont.IComponentFactory = &compcont.TypedSimpleComponentFactory[IPAllowConfig, Middleware]{
+	TypeID: IPAllowTypeID,
+	CreateInstanceFunc: func(ctx compcont.BuildContext, cfg IPAllowConfig) (instance Middleware, err error) {
+		prefixes, err := ParsePrefixes(cfg.Allow)
+		if err != nil {
+			return
+		}
+		proxies, err := ParsePrefixes(cfg.TrustedProxies)
+		if err != nil {
+			return
+		}
+		instance = IPAllow(prefixes, cfg.TrustForwardedFor, proxies...)
+		return
+	},
+}
+
+type ChainConfig struct {
+	Middlewares []*httprouter.MiddlewareRef `ccf:"middlewares"`
+}
+
+// 将多个中间件组合为一个，第一个位于最外层
+var chainFactory compcont.IComponentFactory = &compcont.TypedSimpleComponentFactory[ChainConfig, Middleware]{
+	TypeID: ChainTypeID,
+	CreateInstanceFunc: func(ctx compcont.BuildContext, cfg ChainConfig) (instance Middleware, err error) {
+		var mws []Middleware
+		for _, ref := range cfg.Middlewares {
+			mws = append(mws, ref.MustLoadComponent(ctx.Container).Instance)
+		}
+		instance = func(next http.Handler) http.Handler {
+			return httprouter.Chain(next, mws...)
+		}
+		return
+	},
+}
+
+func MustRegister(registry compcont.IFactoryRegistry) {
+	for _, f := range []compcont.IComponentFactory{
+		recoveryFactory,
+		corsFactory,
+		bodyLimitFactory,
+		timeoutFactory,
+		gzipFactory,
+		authFactory,
+		ipAllowFactory,
+		chainFactory,
+	} {
+		compcont.MustRegister(registry, f)
+	}
+}
+
+func init() {
+	MustRegister(compcont.DefaultFactoryRegistry)
+}
@@ -0,0 +1,89 @@
+package httpmiddleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-compcont/compcont-core"
+	"github.com/go-compcont/compcont-std/reloading"
+	"github.com/stretchr/testify/assert"
+)
+
+// 记录监听与关闭的IReloading
+type fakeReloading struct {
+	listeners map[int]reloading.OnReloadingListener
+	nextID    int
+	closed    bool
+}
+
+func (f *fakeReloading) Load(ctx context.Context) []byte {
+	return []byte(`{"bearer":["token"]}`)
+}
+
+func (f *fakeReloading) AddOnReloadingListener(listener reloading.OnReloadingListener) int {
+	f.nextID++
+	f.listeners[f.nextID] = listener
+	return f.nextID
+}
+
+func (f *fakeReloading) RemoveOnReloadingListener(id int) {
+	delete(f.listeners, id)
+}
+
+func (f *fakeReloading) Close() error {
+	f.closed = true
+	return nil
+}
+
+func TestAuthComponentDestroy(t *testing.T) {
+	var created []*fakeReloading
+	registry := compcont.NewFactoryRegistry()
+	MustRegister(registry)
+	compcont.MustRegister(registry, &compcont.TypedSimpleComponentFactory[struct{}, reloading.IReloading]{
+		TypeID: "test.reloading",
+		CreateInstanceFunc: func(ctx compcont.BuildContext, config struct{}) (reloading.IReloading, error) {
+			f := &fakeReloading{listeners: map[int]reloading.OnReloadingListener{}}
+			created = append(created, f)
+			return f, nil
+		},
+	})
+	cc := compcont.NewComponentContainer(compcont.WithFactoryRegistry(registry))
+	if !assert.NoError(t, cc.LoadNamedComponents([]compcont.ComponentConfig{
+		{Name: "creds", Type: "test.reloading"},
+		{Name: "shared", Type: AuthTypeID, Deps: []compcont.ComponentName{"creds"}, Config: map[string]any{
+			"credentials": map[string]any{"reloading": map[string]any{"refer": "creds"}},
+		}},
+		{Name: "inline", Type: AuthTypeID, Config: map[string]any{
+			"credentials": map[string]any{"reloading": map[string]any{"type": "test.reloading"}},
+		}},
+	})) {
+		return
+	}
+	if !assert.Len(t, created, 2) {
+		return
+	}
+	shared, inline := created[0], created[1]
+
+	for _, name := range []compcont.ComponentName{"shared", "inline"} {
+		c, err := cc.GetComponent(name)
+		if !assert.NoError(t, err) {
+			return
+		}
+		mw := c.Instance.(Middleware)
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("Authorization", "Bearer token")
+		assert.Equal(t, http.StatusOK, serve(mw(ok), req).Code, name)
+
+		factory, err := registry.GetFactory(AuthTypeID)
+		if !assert.NoError(t, err) {
+			return
+		}
+		assert.NoError(t, factory.DestroyInstance(c.Context, c.Instance))
+	}
+	// 引用的Reloading只取消监听，内联的随组件关闭
+	assert.Empty(t, shared.listeners)
+	assert.False(t, shared.closed)
+	assert.True(t, inline.closed)
+}
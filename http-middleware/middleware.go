@@ -0,0 +1,395 @@
+package httpmiddleware
+
+import (
+	"compress/gzip"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"runtime/debug"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	httprouter "github.com/go-compcont/compcont-std/http-router"
+	"go.uber.org/zap"
+)
+
+type Middleware = httprouter.Middleware
+
+// Recovery 捕获handler的panic并输出日志，返回500
+func Recovery(logger *zap.Logger, stacktrace bool) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			defer func() {
+				rec := recover()
+				if rec == nil {
+					return
+				}
+				if rec == http.ErrAbortHandler {
+					panic(rec)
+				}
+				fields := []zap.Field{
+					zap.Any("panic", rec),
+					zap.String("method", r.Method),
+					zap.String("path", r.URL.Path),
+				}
+				if stacktrace {
+					fields = append(fields, zap.ByteString("stack", debug.Stack()))
+				}
+				logger.Error("http handler panic", fields...)
+				http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			}()
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+type CORSConfig struct {
+	AllowedOrigins   []string      `ccf:"allowed_origins"` // "*"表示所有来源
+	AllowedMethods   []string      `ccf:"allowed_methods"` // 默认GET、POST、HEAD
+	AllowedHeaders   []string      `ccf:"allowed_headers"`
+	ExposedHeaders   []string      `ccf:"exposed_headers"`
+	AllowCredentials bool          `ccf:"allow_credentials"`
+	MaxAge           time.Duration `ccf:"max_age"` // 预检结果的缓存时间
+}
+
+// CORS 处理跨域请求与预检请求
+func CORS(cfg CORSConfig) Middleware {
+	methods := cfg.AllowedMethods
+	if len(methods) == 0 {
+		methods = []string{http.MethodGet, http.MethodPost, http.MethodHead}
+	}
+	allowAll := slices.Contains(cfg.AllowedOrigins, "*")
+	allowed := func(origin string) bool {
+		return allowAll || slices.Contains(cfg.AllowedOrigins, origin)
+	}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			origin := r.Header.Get("Origin")
+			h := w.Header()
+			h.Add("Vary", "Origin")
+			if origin == "" || !allowed(origin) {
+				next.ServeHTTP(w, r)
+				return
+			}
+			if allowAll && !cfg.AllowCredentials {
+				h.Set("Access-Control-Allow-Origin", "*")
+			} else {
+				h.Set("Access-Control-Allow-Origin", origin)
+			}
+			if cfg.AllowCredentials {
+				h.Set("Access-Control-Allow-Credentials", "true")
+			}
+
+			if r.Method != http.MethodOptions || r.Header.Get("Access-Control-Request-Method") == "" {
+				if len(cfg.ExposedHeaders) > 0 {
+					h.Set("Access-Control-Expose-Headers", strings.Join(cfg.ExposedHeaders, ", "))
+				}
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			// 预检请求
+			h.Add("Vary", "Access-Control-Request-Method")
+			h.Add("Vary", "Access-Control-Request-Headers")
+			h.Set("Access-Control-Allow-Methods", strings.Join(methods, ", "))
+			if len(cfg.AllowedHeaders) > 0 {
+				h.Set("Access-Control-Allow-Headers", strings.Join(cfg.AllowedHeaders, ", "))
+			} else if reqHeaders := r.Header.Get("Access-Control-Request-Headers"); reqHeaders != "" {
+				h.Set("Access-Control-Allow-Headers", reqHeaders)
+			}
+			if cfg.MaxAge > 0 {
+				h.Set("Access-Control-Max-Age", strconv.Itoa(int(cfg.MaxAge.Seconds())))
+			}
+			w.WriteHeader(http.StatusNoContent)
+		})
+	}
+}
+
+// BodyLimit 限制请求体大小，超出时返回413
+func BodyLimit(maxBytes int64) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.ContentLength > maxBytes {
+				http.Error(w, http.StatusText(http.StatusRequestEntityTooLarge), http.StatusRequestEntityTooLarge)
+				return
+			}
+			r.Body = http.MaxBytesReader(w, r.Body, maxBytes)
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// Timeout 限制handler的处理时间，超时返回503
+func Timeout(timeout time.Duration, message string) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.TimeoutHandler(next, timeout, message)
+	}
+}
+
+var gzipWriterPools sync.Map // level -> *sync.Pool
+
+func gzipPool(level int) *sync.Pool {
+	if p, ok := gzipWriterPools.Load(level); ok {
+		return p.(*sync.Pool)
+	}
+	p, _ := gzipWriterPools.LoadOrStore(level, &sync.Pool{New: func() any {
+		w, _ := gzip.NewWriterLevel(nil, level)
+		return w
+	}})
+	return p.(*sync.Pool)
+}
+
+type gzipResponseWriter struct {
+	http.ResponseWriter
+	pool        *sync.Pool
+	gz          *gzip.Writer
+	wroteHeader bool
+}
+
+func (w *gzipResponseWriter) WriteHeader(status int) {
+	if w.wroteHeader {
+		return
+	}
+	w.wroteHeader = true
+	h := w.Header()
+	// 已经编码、无响应体时不压缩
+	if h.Get("Content-Encoding") == "" && status != http.StatusNoContent && status != http.StatusNotModified {
+		h.Set("Content-Encoding", "gzip")
+		h.Del("Content-Length")
+		w.gz = w.pool.Get().(*gzip.Writer)
+		w.gz.Reset(w.ResponseWriter)
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *gzipResponseWriter) Write(p []byte) (int, error) {
+	if !w.wroteHeader {
+		if w.Header().Get("Content-Type") == "" {
+			w.Header().Set("Content-Type", http.DetectContentType(p))
+		}
+		w.WriteHeader(http.StatusOK)
+	}
+	if w.gz == nil {
+		return w.ResponseWriter.Write(p)
+	}
+	return w.gz.Write(p)
+}
+
+func (w *gzipResponseWriter) Flush() {
+	if w.gz != nil {
+		_ = w.gz.Flush()
+	}
+	_ = http.NewResponseController(w.ResponseWriter).Flush()
+}
+
+func (w *gzipResponseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+func (w *gzipResponseWriter) close() {
+	if w.gz == nil {
+		return
+	}
+	_ = w.gz.Close()
+	w.gz.Reset(nil)
+	w.pool.Put(w.gz)
+	w.gz = nil
+}
+
+// Gzip 客户端支持时压缩响应体，level为gzip.DefaultCompression等
+func Gzip(level int) (Middleware, error) {
+	if _, err := gzip.NewWriterLevel(nil, level); err != nil {
+		return nil, err
+	}
+	pool := gzipPool(level)
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Add("Vary", "Accept-Encoding")
+			if !acceptsGzip(r.Header.Get("Accept-Encoding")) || r.Method == http.MethodHead {
+				next.ServeHTTP(w, r)
+				return
+			}
+			gw := &gzipResponseWriter{ResponseWriter: w, pool: pool}
+			defer gw.close()
+			next.ServeHTTP(gw, r)
+		})
+	}, nil
+}
+
+func acceptsGzip(header string) bool {
+	for _, part := range strings.Split(header, ",") {
+		coding, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		if strings.TrimSpace(coding) != "gzip" {
+			continue
+		}
+		return strings.ReplaceAll(strings.TrimSpace(params), " ", "") != "q=0"
+	}
+	return false
+}
+
+// 认证使用的凭据，通过ReloadingConfig加载，变更后立即生效
+type Credentials struct {
+	Basic  map[string]string `json:"basic" yaml:"basic"`   // 用户名 -> 密码
+	Bearer []string          `json:"bearer" yaml:"bearer"` // 允许的bearer token
+}
+
+// 当前生效的凭据
+type CredentialsStore struct {
+	current atomic.Pointer[Credentials]
+}
+
+func (s *CredentialsStore) Store(c Credentials) {
+	s.current.Store(&c)
+}
+
+func (s *CredentialsStore) Load() Credentials {
+	if c := s.current.Load(); c != nil {
+		return *c
+	}
+	return Credentials{}
+}
+
+func secureEqual(a, b string) bool {
+	return subtle.ConstantTimeCompare([]byte(a), []byte(b)) == 1
+}
+
+func (c Credentials) verify(r *http.Request) bool {
+	auth := r.Header.Get("Authorization")
+	scheme, value, _ := strings.Cut(auth, " ")
+	switch strings.ToLower(scheme) {
+	case "basic":
+		user, password, ok := r.BasicAuth()
+		if !ok {
+			return false
+		}
+		expected, exists := c.Basic[user]
+		// 用户不存在时也进行比较，避免通过耗时判断用户是否存在
+		return secureEqual(password, expected) && exists
+	case "bearer":
+		matched := false
+		for _, token := range c.Bearer {
+			if secureEqual(strings.TrimSpace(value), token) {
+				matched = true
+			}
+		}
+		return matched
+	}
+	return false
+}
+
+// Auth 校验basic或bearer认证，失败返回401
+func Auth(store *CredentialsStore, realm string) Middleware {
+	if realm == "" {
+		realm = "restricted"
+	}
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			creds := store.Load()
+			if creds.verify(r) {
+				next.ServeHTTP(w, r)
+				return
+			}
+			if len(creds.Basic) > 0 {
+				w.Header().Set("WWW-Authenticate", fmt.Sprintf("Basic realm=%q", realm))
+			} else {
+				w.Header().Set("WWW-Authenticate", fmt.Sprintf("Bearer realm=%q", realm))
+			}
+			http.Error(w, http.StatusText(http.StatusUnauthorized), http.StatusUnauthorized)
+		})
+	}
+}
+
+// ParsePrefixes 解析CIDR或单个IP
+func ParsePrefixes(items []string) (prefixes []netip.Prefix, err error) {
+	for _, item := range items {
+		if !strings.Contains(item, "/") {
+			addr, err := netip.ParseAddr(item)
+			if err != nil {
+				return nil, err
+			}
+			prefixes = append(prefixes, netip.PrefixFrom(addr, addr.BitLen()))
+			continue
+		}
+		prefix, err := netip.ParsePrefix(item)
+		if err != nil {
+			return nil, err
+		}
+		prefixes = append(prefixes, prefix.Masked())
+	}
+	return
+}
+
+var errNoClientIP = errors.New("no client ip")
+
+func remoteIP(r *http.Request) (addr netip.Addr, err error) {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	if host == "" {
+		return addr, errNoClientIP
+	}
+	addr, err = netip.ParseAddr(host)
+	return addr.Unmap(), err
+}
+
+func containsAddr(prefixes []netip.Prefix, addr netip.Addr) bool {
+	for _, p := range prefixes {
+		if p.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+// 客户端地址。信任X-Forwarded-For时从右向左跳过可信代理，取第一个不属于可信代理的地址：
+// trustedProxies为空时直接连接的对端视为唯一的可信代理，取最右侧的地址；
+// 否则仅当直接连接的对端属于trustedProxies时才使用X-Forwarded-For。
+// 最左侧的地址可以由客户端任意伪造，不会被单独信任
+func clientIP(r *http.Request, trustForwardedFor bool, trustedProxies []netip.Prefix) (addr netip.Addr, err error) {
+	if addr, err = remoteIP(r); err != nil || !trustForwardedFor {
+		return
+	}
+	if len(trustedProxies) > 0 && !containsAddr(trustedProxies, addr) {
+		return
+	}
+	var hops []string
+	for _, header := range r.Header.Values("X-Forwarded-For") {
+		hops = append(hops, strings.Split(header, ",")...)
+	}
+	for i := len(hops) - 1; i >= 0; i-- {
+		hop, err := netip.ParseAddr(strings.TrimSpace(hops[i]))
+		if err != nil {
+			return netip.Addr{}, err
+		}
+		addr = hop.Unmap()
+		if len(trustedProxies) == 0 || !containsAddr(trustedProxies, addr) {
+			return addr, nil
+		}
+	}
+	// 全部是可信代理时使用最左侧的地址
+	return
+}
+
+// IPAllow 仅允许来自prefixes的请求，其他返回403。
+// trustForwardedFor为true时使用X-Forwarded-For中最右侧不属于trustedProxies的地址，仅应在可信的反向代理之后开启，
+// 指定trustedProxies时只接受来自这些代理的X-Forwarded-For
+func IPAllow(prefixes []netip.Prefix, trustForwardedFor bool, trustedProxies ...netip.Prefix) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			addr, err := clientIP(r, trustForwardedFor, trustedProxies)
+			if err == nil && containsAddr(prefixes, addr) {
+				next.ServeHTTP(w, r)
+				return
+			}
+			http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+		})
+	}
+}
@@ -0,0 +1,124 @@
+package httpmiddleware
+
+import (
+	"compress/gzip"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+var ok = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	_, _ = io.WriteString(w, strings.Repeat("ok", 100))
+})
+
+func serve(h http.Handler, r *http.Request) *httptest.ResponseRecorder {
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, r)
+	return rec
+}
+
+func TestMiddlewares(t *testing.T) {
+	t.Run("recovery", func(t *testing.T) {
+		h := Recovery(zap.NewNop(), true)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) { panic("boom") }))
+		assert.Equal(t, http.StatusInternalServerError, serve(h, httptest.NewRequest(http.MethodGet, "/", nil)).Code)
+	})
+
+	t.Run("gzip", func(t *testing.T) {
+		mw, err := Gzip(gzip.BestSpeed)
+		assert.NoError(t, err)
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("Accept-Encoding", "br, gzip")
+		rec := serve(mw(ok), req)
+		assert.Equal(t, "gzip", rec.Header().Get("Content-Encoding"))
+		zr, err := gzip.NewReader(rec.Body)
+		assert.NoError(t, err)
+		body, _ := io.ReadAll(zr)
+		assert.Equal(t, strings.Repeat("ok", 100), string(body))
+	})
+
+	t.Run("auth", func(t *testing.T) {
+		store := &CredentialsStore{}
+		store.Store(Credentials{Basic: map[string]string{"admin": "secret"}, Bearer: []string{"token"}})
+		h := Auth(store, "")(ok)
+
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		assert.Equal(t, http.StatusUnauthorized, serve(h, req).Code)
+		req.SetBasicAuth("admin", "secret")
+		assert.Equal(t, http.StatusOK, serve(h, req).Code)
+		req.Header.Set("Authorization", "Bearer token")
+		assert.Equal(t, http.StatusOK, serve(h, req).Code)
+
+		store.Store(Credentials{})
+		assert.Equal(t, http.StatusUnauthorized, serve(h, req).Code)
+	})
+
+	t.Run("ip-allow", func(t *testing.T) {
+		prefixes, err := ParsePrefixes([]string{"10.0.0.0/8", "::1"})
+		assert.NoError(t, err)
+		h := IPAllow(prefixes, false)(ok)
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.RemoteAddr = "10.1.2.3:5000"
+		assert.Equal(t, http.StatusOK, serve(h, req).Code)
+		req.RemoteAddr = "192.168.0.1:5000"
+		assert.Equal(t, http.StatusForbidden, serve(h, req).Code)
+	})
+
+	t.Run("ip-allow forwarded", func(t *testing.T) {
+		prefixes, err := ParsePrefixes([]string{"10.0.0.0/8"})
+		assert.NoError(t, err)
+		proxies, err := ParsePrefixes([]string{"192.168.0.0/16"})
+		assert.NoError(t, err)
+		direct := IPAllow(prefixes, true)(ok)
+		trusted := IPAllow(prefixes, true, proxies...)(ok)
+
+		for _, c := range []struct {
+			name   string
+			h      http.Handler
+			remote string
+			xff    []string
+			code   int
+		}{
+			// 客户端伪造的最左侧地址不能绕过白名单
+			{"spoofed left-most", direct, "192.168.0.1:5000", []string{"10.0.0.1, 203.0.113.7"}, http.StatusForbidden},
+			{"right-most", direct, "192.168.0.1:5000", []string{"203.0.113.7, 10.0.0.1"}, http.StatusOK},
+			{"multiple headers", direct, "192.168.0.1:5000", []string{"203.0.113.7", "10.0.0.1"}, http.StatusOK},
+			{"no header", direct, "10.1.1.1:5000", nil, http.StatusOK},
+			{"invalid", direct, "10.1.1.1:5000", []string{"bogus"}, http.StatusForbidden},
+			{"skip trusted proxies", trusted, "192.168.0.1:5000", []string{"203.0.113.7, 10.0.0.1, 192.168.0.2"}, http.StatusOK},
+			{"spoofed behind proxies", trusted, "192.168.0.1:5000", []string{"10.0.0.1, 203.0.113.7, 192.168.0.2"}, http.StatusForbidden},
+			{"untrusted peer", trusted, "203.0.113.9:5000", []string{"10.0.0.1"}, http.StatusForbidden},
+			{"untrusted allowed peer", trusted, "10.2.2.2:5000", []string{"203.0.113.7"}, http.StatusOK},
+		} {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req.RemoteAddr = c.remote
+			for _, v := range c.xff {
+				req.Header.Add("X-Forwarded-For", v)
+			}
+			assert.Equal(t, c.code, serve(c.h, req).Code, c.name)
+		}
+	})
+
+	t.Run("cors", func(t *testing.T) {
+		h := CORS(CORSConfig{AllowedOrigins: []string{"https://a.example"}, AllowedMethods: []string{"PUT"}})(ok)
+		req := httptest.NewRequest(http.MethodOptions, "/", nil)
+		req.Header.Set("Origin", "https://a.example")
+		req.Header.Set("Access-Control-Request-Method", "PUT")
+		rec := serve(h, req)
+		assert.Equal(t, http.StatusNoContent, rec.Code)
+		assert.Equal(t, "https://a.example", rec.Header().Get("Access-Control-Allow-Origin"))
+		assert.Equal(t, "PUT", rec.Header().Get("Access-Control-Allow-Methods"))
+
+		req.Header.Set("Origin", "https://b.example")
+		assert.Empty(t, serve(h, req).Header().Get("Access-Control-Allow-Origin"))
+	})
+
+	t.Run("body-limit", func(t *testing.T) {
+		h := BodyLimit(3)(ok)
+		assert.Equal(t, http.StatusRequestEntityTooLarge, serve(h, httptest.NewRequest(http.MethodPost, "/", strings.NewReader("toolong"))).Code)
+	})
+}
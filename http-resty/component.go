@@ -0,0 +1,37 @@
+package httpresty
+
+import (
+	"github.com/go-compcont/compcont-core"
+	compcontzap "github.com/go-compcont/compcont-std/compcont-zap"
+	"github.com/go-compcont/compcont-std/tracing"
+	"github.com/go-resty/resty/v2"
+)
+
+const TypeID compcont.ComponentTypeID = "std.http.resty"
+
+var factory compcont.IComponentFactory = &compcont.TypedSimpleComponentFactory[Config, *resty.Client]{
+	TypeID: TypeID,
+	CreateInstanceFunc: func(ctx compcont.BuildContext, cfg Config) (instance *resty.Client, err error) {
+		instance, err = New(cfg, compcontzap.ComponentLogger(ctx, cfg.Logger))
+		if err != nil {
+			return
+		}
+		if cfg.Tracing != nil {
+			tp := cfg.Tracing.MustLoadComponent(ctx.Container).Instance
+			instance.SetTransport(tracing.Transport(tp, instance.GetClient().Transport))
+		}
+		return
+	},
+	DestroyInstanceFunc: func(ctx compcont.BuildContext, instance *resty.Client) (err error) {
+		instance.GetClient().CloseIdleConnections()
+		return
+	},
+}
+
+func MustRegister(registry compcont.IFactoryRegistry) {
+	compcont.MustRegister(registry, factory)
+}
+
+func init() {
+	MustRegister(compcont.DefaultFactoryRegistry)
+}
@@ -0,0 +1,202 @@
+package httpresty
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-compcont/compcont-core"
+	"github.com/go-resty/resty/v2"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+type RetryConfig struct {
+	Count       int           `ccf:"count"`         // 重试次数，0表示不重试
+	WaitTime    time.Duration `ccf:"wait_time"`     // 首次重试的等待时间，之后指数退避，默认100ms
+	MaxWaitTime time.Duration `ccf:"max_wait_time"` // 最大等待时间，默认2s
+	// 重试条件："5xx"、"4xx"等状态码类别，"429"等具体状态码，"timeout"表示超时错误，
+	// 请求错误总是会重试，不填为["5xx", "429"]
+	Conditions []string `ccf:"conditions"`
+}
+
+type TLSConfig struct {
+	CAFile             string `ccf:"ca_file"`   // 额外信任的CA证书(PEM)
+	CertFile           string `ccf:"cert_file"` // 客户端证书(PEM)
+	KeyFile            string `ccf:"key_file"`  // 客户端证书私钥(PEM)
+	ServerName         string `ccf:"server_name"`
+	InsecureSkipVerify bool   `ccf:"insecure_skip_verify"`
+}
+
+type Config struct {
+	BaseURL string            `ccf:"base_url"`
+	Headers map[string]string `ccf:"headers"` // 所有请求默认携带的header
+	Proxy   string            `ccf:"proxy"`   // 代理地址，不填使用环境变量HTTP_PROXY等
+
+	Timeout               time.Duration `ccf:"timeout"`                 // 整个请求的超时，0表示不限制
+	DialTimeout           time.Duration `ccf:"dial_timeout"`            // 默认30s
+	TLSHandshakeTimeout   time.Duration `ccf:"tls_handshake_timeout"`   // 默认10s
+	ResponseHeaderTimeout time.Duration `ccf:"response_header_timeout"` // 0表示不限制
+	IdleConnTimeout       time.Duration `ccf:"idle_conn_timeout"`       // 默认90s
+	MaxIdleConnsPerHost   int           `ccf:"max_idle_conns_per_host"`
+
+	Retry RetryConfig `ccf:"retry"`
+	TLS   *TLSConfig  `ccf:"tls"`
+
+	Debug  bool                                             `ccf:"debug"`  // 以debug级别输出请求与响应
+	Logger *compcont.TypedComponentConfig[any, *zap.Logger] `ccf:"logger"` // resty的日志输出，不填使用默认logger
+
+	Tracing *compcont.TypedComponentConfig[any, trace.TracerProvider] `ccf:"tracing"` // 引用std.tracing时为每个请求创建client span
+}
+
+func (c *TLSConfig) build() (cfg *tls.Config, err error) {
+	cfg = &tls.Config{
+		ServerName:         c.ServerName,
+		InsecureSkipVerify: c.InsecureSkipVerify,
+	}
+	if c.CAFile != "" {
+		pem, err := os.ReadFile(c.CAFile)
+		if err != nil {
+			return nil, err
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificate found in %s", c.CAFile)
+		}
+		cfg.RootCAs = pool
+	}
+	if c.CertFile != "" || c.KeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(c.CertFile, c.KeyFile)
+		if err != nil {
+			return nil, err
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+	}
+	return
+}
+
+func (c *Config) transport() (transport *http.Transport, err error) {
+	transport = http.DefaultTransport.(*http.Transport).Clone()
+	dialTimeout := c.DialTimeout
+	if dialTimeout <= 0 {
+		dialTimeout = 30 * time.Second
+	}
+	transport.DialContext = (&net.Dialer{Timeout: dialTimeout, KeepAlive: 30 * time.Second}).DialContext
+	if c.TLSHandshakeTimeout > 0 {
+		transport.TLSHandshakeTimeout = c.TLSHandshakeTimeout
+	}
+	if c.IdleConnTimeout > 0 {
+		transport.IdleConnTimeout = c.IdleConnTimeout
+	}
+	if c.MaxIdleConnsPerHost > 0 {
+		transport.MaxIdleConnsPerHost = c.MaxIdleConnsPerHost
+	}
+	transport.ResponseHeaderTimeout = c.ResponseHeaderTimeout
+	if c.Proxy != "" {
+		proxy, err := url.Parse(c.Proxy)
+		if err != nil {
+			return nil, fmt.Errorf("invalid proxy: %w", err)
+		}
+		transport.Proxy = http.ProxyURL(proxy)
+	}
+	if c.TLS != nil {
+		if transport.TLSClientConfig, err = c.TLS.build(); err != nil {
+			return nil, fmt.Errorf("tls: %w", err)
+		}
+	}
+	return
+}
+
+func isTimeout(err error) bool {
+	var netErr net.Error
+	return errors.As(err, &netErr) && netErr.Timeout()
+}
+
+func retryCondition(cond string) (fn resty.RetryConditionFunc, err error) {
+	cond = strings.ToLower(strings.TrimSpace(cond))
+	switch {
+	case cond == "timeout":
+		return func(r *resty.Response, err error) bool { return err != nil && isTimeout(err) }, nil
+	case len(cond) == 3 && strings.HasSuffix(cond, "xx") && cond[0] >= '1' && cond[0] <= '5':
+		class := int(cond[0] - '0')
+		return func(r *resty.Response, err error) bool { return r != nil && r.StatusCode()/100 == class }, nil
+	default:
+		code, err := strconv.Atoi(cond)
+		if err != nil || code < 100 || code > 599 {
+			return nil, fmt.Errorf("unknown retry condition: %s", cond)
+		}
+		return func(r *resty.Response, err error) bool { return r != nil && r.StatusCode() == code }, nil
+	}
+}
+
+// 将resty的日志输出到zap
+type zapLogger struct {
+	logger *zap.SugaredLogger
+}
+
+func (l *zapLogger) Errorf(format string, v ...any) {
+	l.logger.Errorf(strings.TrimRight(format, "\n"), v...)
+}
+
+func (l *zapLogger) Warnf(format string, v ...any) {
+	l.logger.Warnf(strings.TrimRight(format, "\n"), v...)
+}
+
+func (l *zapLogger) Debugf(format string, v ...any) {
+	l.logger.Debugf(strings.TrimRight(format, "\n"), v...)
+}
+
+// New 按照配置构造resty.Client，logger为空时resty使用自带的日志输出
+func New(cfg Config, logger *zap.Logger) (client *resty.Client, err error) {
+	transport, err := cfg.transport()
+	if err != nil {
+		return
+	}
+	client = resty.New().SetTransport(transport)
+	if logger != nil {
+		client.SetLogger(&zapLogger{logger: logger.WithOptions(zap.AddCallerSkip(1)).Sugar()})
+	}
+	if cfg.BaseURL != "" {
+		client.SetBaseURL(cfg.BaseURL)
+	}
+	if len(cfg.Headers) > 0 {
+		client.SetHeaders(cfg.Headers)
+	}
+	if cfg.Timeout > 0 {
+		client.SetTimeout(cfg.Timeout)
+	}
+	client.SetDebug(cfg.Debug)
+
+	if r := cfg.Retry; r.Count > 0 {
+		client.SetRetryCount(r.Count)
+		if r.WaitTime > 0 {
+			client.SetRetryWaitTime(r.WaitTime)
+		}
+		if r.MaxWaitTime > 0 {
+			client.SetRetryMaxWaitTime(r.MaxWaitTime)
+		}
+		conditions := r.Conditions
+		if len(conditions) == 0 {
+			conditions = []string{"5xx", "429"}
+		}
+		for _, cond := range conditions {
+			fn, err := retryCondition(cond)
+			if err != nil {
+				return nil, err
+			}
+			client.AddRetryCondition(fn)
+		}
+	}
+	return
+}
@@ -0,0 +1,39 @@
+package httpresty
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRetry(t *testing.T) {
+	var calls atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "v", r.Header.Get("X-Test"))
+		if calls.Add(1) < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write([]byte("ok"))
+	}))
+	defer srv.Close()
+
+	client, err := New(Config{
+		BaseURL: srv.URL,
+		Headers: map[string]string{"X-Test": "v"},
+		Retry:   RetryConfig{Count: 3, WaitTime: time.Millisecond, MaxWaitTime: time.Millisecond},
+	}, nil)
+	assert.NoError(t, err)
+
+	resp, err := client.R().Get("/")
+	assert.NoError(t, err)
+	assert.Equal(t, "ok", resp.String())
+	assert.Equal(t, int32(3), calls.Load())
+
+	_, err = New(Config{Retry: RetryConfig{Count: 1, Conditions: []string{"bogus"}}}, nil)
+	assert.Error(t, err)
+}
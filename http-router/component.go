@@ -0,0 +1,54 @@
+package httprouter
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/go-compcont/compcont-core"
+)
+
+const TypeID compcont.ComponentTypeID = "std.http.router"
+
+func loadMiddlewares(ctx compcont.BuildContext, refs []*MiddlewareRef) (mws []Middleware) {
+	for _, ref := range refs {
+		mws = append(mws, ref.MustLoadComponent(ctx.Container).Instance)
+	}
+	return
+}
+
+// 实例为http.Handler，可挂载到std.http.server或其他router
+var factory compcont.IComponentFactory = &compcont.TypedSimpleComponentFactory[Config, http.Handler]{
+	TypeID: TypeID,
+	CreateInstanceFunc: func(ctx compcont.BuildContext, cfg Config) (instance http.Handler, err error) {
+		r := New()
+		for i, route := range cfg.Routes {
+			if route.Handler == nil {
+				return nil, fmt.Errorf("routes[%d]: handler is required", i)
+			}
+			h := route.Handler.MustLoadComponent(ctx.Container).Instance
+			if err = r.Handle(route.Method, route.Pattern, h, loadMiddlewares(ctx, route.Middlewares)...); err != nil {
+				return nil, fmt.Errorf("routes[%d]: %w", i, err)
+			}
+		}
+		for i, mount := range cfg.Mounts {
+			if mount.Handler == nil {
+				return nil, fmt.Errorf("mounts[%d]: handler is required", i)
+			}
+			h := mount.Handler.MustLoadComponent(ctx.Container).Instance
+			if err = r.Mount(mount.Prefix, h, loadMiddlewares(ctx, mount.Middlewares)...); err != nil {
+				return nil, fmt.Errorf("mounts[%d]: %w", i, err)
+			}
+		}
+		r.Use(loadMiddlewares(ctx, cfg.Middlewares)...)
+		instance = r
+		return
+	},
+}
+
+func MustRegister(registry compcont.IFactoryRegistry) {
+	compcont.MustRegister(registry, factory)
+}
+
+func init() {
+	MustRegister(compcont.DefaultFactoryRegistry)
+}
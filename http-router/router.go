@@ -0,0 +1,95 @@
+package httprouter
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/go-compcont/compcont-core"
+)
+
+// 中间件组件的实例类型，第一个中间件位于最外层
+type Middleware = func(http.Handler) http.Handler
+
+type MiddlewareRef = compcont.TypedComponentConfig[any, Middleware]
+
+type RouteConfig struct {
+	Method      string                                            `ccf:"method"`  // 不填匹配所有方法
+	Pattern     string                                            `ccf:"pattern"` // http.ServeMux的pattern，例如"/items/{id}"
+	Handler     *compcont.TypedComponentConfig[any, http.Handler] `ccf:"handler"` // 必填
+	Middlewares []*MiddlewareRef                                  `ccf:"middlewares"`
+}
+
+// 在前缀下挂载其他router(例如子容器中的std.http.router)或handler，转发时去掉前缀
+type MountConfig struct {
+	Prefix      string                                            `ccf:"prefix"`  // 以"/"结尾，例如"/users/"
+	Handler     *compcont.TypedComponentConfig[any, http.Handler] `ccf:"handler"` // 必填
+	Middlewares []*MiddlewareRef                                  `ccf:"middlewares"`
+}
+
+type Config struct {
+	Routes      []RouteConfig    `ccf:"routes"`
+	Mounts      []MountConfig    `ccf:"mounts"`
+	Middlewares []*MiddlewareRef `ccf:"middlewares"` // 作用于所有路由与挂载
+}
+
+// Chain 按顺序组合中间件，mws[0]位于最外层
+func Chain(h http.Handler, mws ...Middleware) http.Handler {
+	for i := len(mws) - 1; i >= 0; i-- {
+		h = mws[i](h)
+	}
+	return h
+}
+
+// Router 基于http.ServeMux的路由
+type Router struct {
+	mux     *http.ServeMux
+	mws     []Middleware
+	handler http.Handler
+}
+
+func New() *Router {
+	r := &Router{mux: http.NewServeMux()}
+	r.handler = r.mux
+	return r
+}
+
+// handle 在ServeMux上注册，pattern非法或冲突时返回错误而不是panic
+func (r *Router) handle(pattern string, h http.Handler) (err error) {
+	defer func() {
+		if rec := recover(); rec != nil {
+			err = fmt.Errorf("%v", rec)
+		}
+	}()
+	r.mux.Handle(pattern, h)
+	return
+}
+
+// Handle 注册路由，method为空时匹配所有方法
+func (r *Router) Handle(method, pattern string, h http.Handler, mws ...Middleware) error {
+	if method != "" {
+		if strings.ContainsAny(strings.TrimSpace(pattern), " \t") {
+			return fmt.Errorf("pattern %q already contains a method", pattern)
+		}
+		pattern = strings.ToUpper(method) + " " + pattern
+	}
+	return r.handle(pattern, Chain(h, mws...))
+}
+
+// Mount 在prefix下挂载handler，handler收到的请求路径去掉了prefix
+func (r *Router) Mount(prefix string, h http.Handler, mws ...Middleware) error {
+	if !strings.HasPrefix(prefix, "/") || !strings.HasSuffix(prefix, "/") {
+		return fmt.Errorf("mount prefix %q must start and end with /", prefix)
+	}
+	return r.handle(prefix, http.StripPrefix(strings.TrimSuffix(prefix, "/"), Chain(h, mws...)))
+}
+
+// Use 添加作用于所有路由的中间件，先添加的位于外层
+func (r *Router) Use(mws ...Middleware) {
+	r.mws = append(r.mws, mws...)
+	r.handler = Chain(r.mux, r.mws...)
+}
+
+func (r *Router) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	r.handler.ServeHTTP(w, req)
+}
@@ -0,0 +1,49 @@
+package httprouter
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func header(key, value string) Middleware {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Add(key, value)
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func TestRouter(t *testing.T) {
+	echo := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = io.WriteString(w, r.URL.Path+" "+r.PathValue("id"))
+	})
+
+	sub := New()
+	assert.NoError(t, sub.Handle(http.MethodGet, "/{id}", echo))
+
+	r := New()
+	assert.NoError(t, r.Handle(http.MethodGet, "/items/{id}", echo, header("X-Order", "route")))
+	assert.NoError(t, r.Mount("/users/", sub, header("X-Order", "mount")))
+	r.Use(header("X-Order", "outer"))
+	assert.Error(t, r.Handle(http.MethodGet, "GET /x", echo))
+	assert.Error(t, r.Mount("/nested", sub))
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/items/7", nil))
+	assert.Equal(t, "/items/7 7", rec.Body.String())
+	assert.Equal(t, []string{"outer", "route"}, rec.Header().Values("X-Order"))
+
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/users/42", nil))
+	assert.Equal(t, "/42 42", rec.Body.String())
+	assert.Equal(t, []string{"outer", "mount"}, rec.Header().Values("X-Order"))
+
+	rec = httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/items/7", nil))
+	assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+}
@@ -0,0 +1,55 @@
+package httpserver
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/go-compcont/compcont-core"
+	compcontzap "github.com/go-compcont/compcont-std/compcont-zap"
+	"github.com/go-compcont/compcont-std/tracing"
+)
+
+const TypeID compcont.ComponentTypeID = "std.http.server"
+
+var factory compcont.IComponentFactory = &compcont.TypedSimpleComponentFactory[Config, *Server]{
+	TypeID: TypeID,
+	CreateInstanceFunc: func(ctx compcont.BuildContext, cfg Config) (instance *Server, err error) {
+		var root http.Handler
+		if cfg.Handler != nil {
+			root = cfg.Handler.MustLoadComponent(ctx.Container).Instance
+		}
+		handler := root
+		if len(cfg.Mounts) > 0 {
+			handlers := make([]http.Handler, len(cfg.Mounts))
+			for i, m := range cfg.Mounts {
+				if m.Handler == nil {
+					err = fmt.Errorf("mounts[%d]: handler is required", i)
+					return
+				}
+				handlers[i] = m.Handler.MustLoadComponent(ctx.Container).Instance
+			}
+			if handler, err = buildMux(root, cfg.Mounts, handlers); err != nil {
+				return
+			}
+		}
+		if handler == nil {
+			err = fmt.Errorf("handler or mounts is required")
+			return
+		}
+		if cfg.Tracing != nil {
+			handler = tracing.Middleware(cfg.Tracing.MustLoadComponent(ctx.Container).Instance)(handler)
+		}
+		return New(cfg, handler, compcontzap.ComponentLogger(ctx, cfg.Logger))
+	},
+	DestroyInstanceFunc: func(ctx compcont.BuildContext, instance *Server) (err error) {
+		return instance.Close()
+	},
+}
+
+func MustRegister(registry compcont.IFactoryRegistry) {
+	compcont.MustRegister(registry, factory)
+}
+
+func init() {
+	MustRegister(compcont.DefaultFactoryRegistry)
+}
@@ -0,0 +1,228 @@
+package httpserver
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-compcont/compcont-core"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+type TLSConfig struct {
+	CertFile string `ccf:"cert_file"`
+	KeyFile  string `ccf:"key_file"`
+	// 检查证书文件是否变化的最小间隔，变化后新的连接使用新证书，默认1m
+	ReloadInterval time.Duration `ccf:"reload_interval"`
+}
+
+type MountConfig struct {
+	Pattern     string                                            `ccf:"pattern"`      // http.ServeMux的pattern，例如"/api/"、"GET /healthz"
+	Handler     *compcont.TypedComponentConfig[any, http.Handler] `ccf:"handler"`      // 必填
+	StripPrefix bool                                              `ccf:"strip_prefix"` // 转发给handler前去掉pattern中的路径前缀
+}
+
+type Config struct {
+	Addr              string        `ccf:"addr"` // 默认":8080"
+	ReadTimeout       time.Duration `ccf:"read_timeout"`
+	ReadHeaderTimeout time.Duration `ccf:"read_header_timeout"`
+	WriteTimeout      time.Duration `ccf:"write_timeout"`
+	IdleTimeout       time.Duration `ccf:"idle_timeout"`
+	MaxHeaderBytes    int           `ccf:"max_header_bytes"`
+	ShutdownTimeout   time.Duration `ccf:"shutdown_timeout"` // 关闭时等待请求处理完成的时间，默认30s
+	TLS               *TLSConfig    `ccf:"tls"`
+
+	Handler *compcont.TypedComponentConfig[any, http.Handler] `ccf:"handler"` // 根handler，配置mounts时挂载在"/"
+	Mounts  []MountConfig                                     `ccf:"mounts"`
+	Logger  *compcont.TypedComponentConfig[any, *zap.Logger]  `ccf:"logger"` // 不填使用默认logger
+
+	Tracing *compcont.TypedComponentConfig[any, trace.TracerProvider] `ccf:"tracing"` // 引用std.tracing时为每个请求创建server span
+}
+
+// 按间隔检查证书文件的修改时间，变化后重新加载
+type certReloader struct {
+	certFile, keyFile string
+	interval          time.Duration
+	logger            *zap.Logger
+
+	mu        sync.Mutex
+	cert      *tls.Certificate
+	modTime   time.Time
+	checkedAt time.Time
+}
+
+func newCertReloader(cfg *TLSConfig, logger *zap.Logger) (r *certReloader, err error) {
+	r = &certReloader{certFile: cfg.CertFile, keyFile: cfg.KeyFile, interval: cfg.ReloadInterval, logger: logger}
+	if r.interval <= 0 {
+		r.interval = time.Minute
+	}
+	if err = r.load(); err != nil {
+		return nil, err
+	}
+	return
+}
+
+func (r *certReloader) latestModTime() (t time.Time, err error) {
+	for _, file := range []string{r.certFile, r.keyFile} {
+		info, err := os.Stat(file)
+		if err != nil {
+			return t, err
+		}
+		if info.ModTime().After(t) {
+			t = info.ModTime()
+		}
+	}
+	return
+}
+
+// 必须持有mu，或在构造时调用
+func (r *certReloader) load() (err error) {
+	modTime, err := r.latestModTime()
+	if err != nil {
+		return
+	}
+	cert, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+	if err != nil {
+		return
+	}
+	r.cert, r.modTime, r.checkedAt = &cert, modTime, time.Now()
+	return
+}
+
+func (r *certReloader) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if time.Since(r.checkedAt) < r.interval {
+		return r.cert, nil
+	}
+	r.checkedAt = time.Now()
+	if modTime, err := r.latestModTime(); err == nil && !modTime.Equal(r.modTime) {
+		// 加载失败时继续使用旧证书
+		if err := r.load(); err != nil {
+			r.logger.Error("reload tls certificate error", zap.Error(err))
+		} else {
+			r.logger.Info("tls certificate reloaded", zap.String("cert_file", r.certFile))
+		}
+	}
+	return r.cert, nil
+}
+
+func buildMux(root http.Handler, mounts []MountConfig, handlers []http.Handler) (mux *http.ServeMux, err error) {
+	defer func() {
+		// pattern非法或冲突时ServeMux会panic
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%v", r)
+		}
+	}()
+	mux = http.NewServeMux()
+	for i, m := range mounts {
+		h := handlers[i]
+		if m.StripPrefix {
+			path := m.Pattern
+			if _, after, ok := strings.Cut(path, " "); ok {
+				path = after
+			}
+			if j := strings.IndexByte(path, '/'); j > 0 {
+				path = path[j:] // 去掉host
+			}
+			h = http.StripPrefix(strings.TrimSuffix(path, "/"), h)
+		}
+		mux.Handle(m.Pattern, h)
+	}
+	if root != nil {
+		mux.Handle("/", root)
+	}
+	return
+}
+
+// Server 监听中的http.Server
+type Server struct {
+	*http.Server
+	listener        net.Listener
+	shutdownTimeout time.Duration
+	logger          *zap.Logger
+	done            chan struct{}
+}
+
+// New 监听地址并在goroutine中开始服务，监听失败时直接返回错误
+func New(cfg Config, handler http.Handler, logger *zap.Logger) (s *Server, err error) {
+	addr := cfg.Addr
+	if addr == "" {
+		addr = ":8080"
+	}
+	// 标准库的错误日志(例如TLS握手失败)以warn级别输出
+	errorLog, err := zap.NewStdLogAt(logger, zap.WarnLevel)
+	if err != nil {
+		return
+	}
+	s = &Server{
+		Server: &http.Server{
+			Addr:              addr,
+			Handler:           handler,
+			ReadTimeout:       cfg.ReadTimeout,
+			ReadHeaderTimeout: cfg.ReadHeaderTimeout,
+			WriteTimeout:      cfg.WriteTimeout,
+			IdleTimeout:       cfg.IdleTimeout,
+			MaxHeaderBytes:    cfg.MaxHeaderBytes,
+			ErrorLog:          errorLog,
+		},
+		shutdownTimeout: cfg.ShutdownTimeout,
+		logger:          logger,
+		done:            make(chan struct{}),
+	}
+	if s.shutdownTimeout <= 0 {
+		s.shutdownTimeout = 30 * time.Second
+	}
+	if cfg.TLS != nil {
+		reloader, err := newCertReloader(cfg.TLS, logger)
+		if err != nil {
+			return nil, fmt.Errorf("tls: %w", err)
+		}
+		s.TLSConfig = &tls.Config{GetCertificate: reloader.GetCertificate}
+	}
+
+	if s.listener, err = net.Listen("tcp", addr); err != nil {
+		return nil, err
+	}
+	useTLS := s.TLSConfig != nil
+	go func() {
+		defer close(s.done)
+		var err error
+		if useTLS {
+			err = s.ServeTLS(s.listener, "", "")
+		} else {
+			err = s.Serve(s.listener)
+		}
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logger.Error("http server stopped", zap.Error(err))
+		}
+	}()
+	logger.Info("http server started", zap.Stringer("addr", s.listener.Addr()), zap.Bool("tls", useTLS))
+	return
+}
+
+// ListenAddr 实际监听的地址，例如配置":0"时获取分配的端口
+func (s *Server) ListenAddr() net.Addr {
+	return s.listener.Addr()
+}
+
+// Close 停止接收新连接，等待处理中的请求完成，超过shutdown_timeout后强制关闭
+func (s *Server) Close() (err error) {
+	ctx, cancel := context.WithTimeout(context.Background(), s.shutdownTimeout)
+	defer cancel()
+	if err = s.Shutdown(ctx); err != nil {
+		s.logger.Warn("http server shutdown timeout, closing connections", zap.Error(err))
+		err = s.Server.Close()
+	}
+	<-s.done
+	s.logger.Info("http server stopped", zap.Stringer("addr", s.listener.Addr()))
+	return
+}
@@ -0,0 +1,69 @@
+package httpserver
+
+import (
+	"io"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+)
+
+func TestServer(t *testing.T) {
+	entered, released := make(chan struct{}), make(chan struct{})
+	slow := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		close(entered)
+		<-released
+		_, _ = w.Write([]byte("slow:" + r.URL.Path))
+	})
+	mux, err := buildMux(nil, []MountConfig{{Pattern: "GET /api/", StripPrefix: true}}, []http.Handler{slow})
+	assert.NoError(t, err)
+
+	s, err := New(Config{Addr: "127.0.0.1:0", ShutdownTimeout: 5 * time.Second}, mux, zap.NewNop())
+	assert.NoError(t, err)
+	shutdown := make(chan struct{})
+	s.RegisterOnShutdown(func() { close(shutdown) })
+
+	body := make(chan string)
+	go func() {
+		resp, err := http.Get("http://" + s.ListenAddr().String() + "/api/items")
+		if !assert.NoError(t, err) {
+			close(body)
+			return
+		}
+		defer resp.Body.Close()
+		data, _ := io.ReadAll(resp.Body)
+		body <- string(data)
+	}()
+
+	// 请求进入handler后关闭，开始shutdown后再让handler返回，处理中的请求应当正常完成
+	<-entered
+	closed := make(chan error)
+	go func() { closed <- s.Close() }()
+	<-shutdown
+	close(released)
+
+	assert.Equal(t, "slow:/items", <-body)
+	assert.NoError(t, <-closed)
+
+	_, err = buildMux(nil, []MountConfig{{Pattern: "/a"}, {Pattern: "/a"}}, []http.Handler{slow, slow})
+	assert.Error(t, err)
+}
+
+func TestServerErrorLog(t *testing.T) {
+	core, logs := observer.New(zapcore.DebugLevel)
+	s, err := New(Config{Addr: "127.0.0.1:0"}, http.NotFoundHandler(), zap.New(core))
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer s.Close()
+
+	s.ErrorLog.Print("http: TLS handshake error")
+	entries := logs.FilterMessage("http: TLS handshake error").All()
+	if assert.Len(t, entries, 1) {
+		assert.Equal(t, zapcore.WarnLevel, entries[0].Level)
+	}
+}
@@ -0,0 +1,23 @@
+package metrics
+
+import (
+	"time"
+
+	"github.com/go-compcont/compcont-core"
+	"github.com/go-compcont/compcont-std/container"
+)
+
+// FactoryRegistry 包装工厂注册器，由其创建的组件(包括嵌套容器中的组件)都会记录构造耗时，
+// 输出为compcont_component_build_duration_seconds{component,type}。
+// 只有使用包装后的注册器构造的组件才会记录：compcont run默认包装，
+// 自行创建容器时需要通过compcont.WithFactoryRegistry传入该方法的返回值
+func (r *Registry) FactoryRegistry(inner compcont.IFactoryRegistry) compcont.IFactoryRegistry {
+	return container.ObserveBuild(inner, func(ctx compcont.BuildContext, d time.Duration, err error) {
+		labels := Labels{"component": container.Path(ctx.GetAbsolutePath()), "type": string(ctx.Config.Type)}
+		r.Gauge("compcont_component_build_duration_seconds", "Duration of the last CreateInstance call of the component.", labels).
+			Set(d.Seconds())
+		if err != nil {
+			r.Counter("compcont_component_build_failures_total", "Number of failed CreateInstance calls of the component.", labels).Inc()
+		}
+	})
+}
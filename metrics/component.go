@@ -0,0 +1,78 @@
+package metrics
+
+import (
+	"net/http"
+	"runtime"
+	"time"
+
+	"github.com/go-compcont/compcont-core"
+)
+
+const TypeID compcont.ComponentTypeID = "std.metrics"
+
+type Config struct {
+	Isolated  bool `ccf:"isolated"`   // 使用独立的registry，默认使用DefaultRegistry
+	GoMetrics bool `ccf:"go_metrics"` // 输出goroutine数量、堆内存与进程启动时间
+}
+
+var processStart = time.Now()
+
+// RegisterGoMetrics 注册运行时指标
+func RegisterGoMetrics(r *Registry) {
+	r.GaugeFunc("go_goroutines", "Number of goroutines that currently exist.", nil, func() float64 {
+		return float64(runtime.NumGoroutine())
+	})
+	r.GaugeFunc("go_memstats_heap_alloc_bytes", "Number of heap bytes allocated and still in use.", nil, func() float64 {
+		var ms runtime.MemStats
+		runtime.ReadMemStats(&ms)
+		return float64(ms.HeapAlloc)
+	})
+	r.GaugeFunc("process_start_time_seconds", "Start time of the process since unix epoch in seconds.", nil, func() float64 {
+		return float64(processStart.UnixNano()) / 1e9
+	})
+}
+
+var factory compcont.IComponentFactory = &compcont.TypedSimpleComponentFactory[Config, *Registry]{
+	TypeID: TypeID,
+	CreateInstanceFunc: func(ctx compcont.BuildContext, cfg Config) (instance *Registry, err error) {
+		instance = DefaultRegistry
+		if cfg.Isolated {
+			instance = NewRegistry()
+		}
+		if cfg.GoMetrics {
+			RegisterGoMetrics(instance)
+		}
+		return
+	},
+}
+
+const HandlerTypeID compcont.ComponentTypeID = "std.metrics.handler"
+
+type HandlerConfig struct {
+	Registry *compcont.TypedComponentConfig[any, *Registry] `ccf:"registry"` // 不填使用DefaultRegistry
+}
+
+// /metrics接口，可挂载到std.http.server或std.http.router
+var handlerFactory compcont.IComponentFactory = &compcont.TypedSimpleComponentFactory[HandlerConfig, http.Handler]{
+	TypeID: HandlerTypeID,
+	CreateInstanceFunc: func(ctx compcont.BuildContext, cfg HandlerConfig) (instance http.Handler, err error) {
+		instance = DefaultRegistry
+		if cfg.Registry != nil {
+			instance = cfg.Registry.MustLoadComponent(ctx.Container).Instance
+		}
+		return
+	},
+}
+
+func MustRegister(registry compcont.IFactoryRegistry) {
+	compcont.MustRegister(registry, factory)
+}
+
+func MustRegisterHandler(registry compcont.IFactoryRegistry) {
+	compcont.MustRegister(registry, handlerFactory)
+}
+
+func init() {
+	MustRegister(compcont.DefaultFactoryRegistry)
+	MustRegisterHandler(compcont.DefaultFactoryRegistry)
+}
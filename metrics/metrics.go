@@ -0,0 +1,303 @@
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+type Labels map[string]string
+
+type metricType string
+
+const (
+	typeCounter metricType = "counter"
+	typeGauge   metricType = "gauge"
+)
+
+// 原子更新的float64
+type value struct {
+	bits atomic.Uint64
+}
+
+func (v *value) load() float64 {
+	return math.Float64frombits(v.bits.Load())
+}
+
+func (v *value) store(f float64) {
+	v.bits.Store(math.Float64bits(f))
+}
+
+func (v *value) add(delta float64) {
+	for {
+		old := v.bits.Load()
+		if v.bits.CompareAndSwap(old, math.Float64bits(math.Float64frombits(old)+delta)) {
+			return
+		}
+	}
+}
+
+// 只增不减的计数
+type Counter struct {
+	v value
+}
+
+func (c *Counter) Inc() {
+	c.v.add(1)
+}
+
+// Add delta必须非负
+func (c *Counter) Add(delta float64) {
+	if delta < 0 {
+		panic("counter cannot decrease")
+	}
+	c.v.add(delta)
+}
+
+func (c *Counter) Value() float64 {
+	return c.v.load()
+}
+
+type Gauge struct {
+	v value
+}
+
+func (g *Gauge) Set(f float64) {
+	g.v.store(f)
+}
+
+func (g *Gauge) Add(delta float64) {
+	g.v.add(delta)
+}
+
+func (g *Gauge) Inc() {
+	g.v.add(1)
+}
+
+func (g *Gauge) Dec() {
+	g.v.add(-1)
+}
+
+func (g *Gauge) Value() float64 {
+	return g.v.load()
+}
+
+type series struct {
+	labels string // 已格式化的标签，例如{a="b",c="d"}
+	read   func() float64
+	owner  any // *Counter、*Gauge，或者func类型时为nil
+}
+
+type family struct {
+	name   string
+	help   string
+	typ    metricType
+	series map[string]*series
+}
+
+// Registry 指标注册表，按名称与标签获取或创建指标，以Prometheus文本格式输出
+type Registry struct {
+	mu       sync.Mutex
+	families map[string]*family
+}
+
+func NewRegistry() *Registry {
+	return &Registry{families: map[string]*family{}}
+}
+
+// 未单独构造registry时组件共享的默认registry
+var DefaultRegistry = NewRegistry()
+
+func validName(name string) bool {
+	if name == "" {
+		return false
+	}
+	for i, c := range name {
+		if !(c == '_' || c == ':' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || i > 0 && c >= '0' && c <= '9') {
+			return false
+		}
+	}
+	return true
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func formatLabels(labels Labels) string {
+	if len(labels) == 0 {
+		return ""
+	}
+	keys := make([]string, 0, len(labels))
+	for k := range labels {
+		if !validName(k) || strings.Contains(k, ":") {
+			panic(fmt.Sprintf("invalid label name: %q", k))
+		}
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	var b strings.Builder
+	b.WriteByte('{')
+	for i, k := range keys {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		fmt.Fprintf(&b, `%s="%s"`, k, labelEscaper.Replace(labels[k]))
+	}
+	b.WriteByte('}')
+	return b.String()
+}
+
+// 获取或创建名称为name的指标族，名称相同而类型不同时panic
+func (r *Registry) family(name, help string, typ metricType) *family {
+	if !validName(name) {
+		panic(fmt.Sprintf("invalid metric name: %q", name))
+	}
+	f, ok := r.families[name]
+	if !ok {
+		f = &family{name: name, help: help, typ: typ, series: map[string]*series{}}
+		r.families[name] = f
+	} else if f.typ != typ {
+		panic(fmt.Sprintf("metric %s already registered as %s", name, f.typ))
+	}
+	return f
+}
+
+// Counter 获取或创建计数器，名称与标签相同的指标已由CounterFunc注册时panic
+func (r *Registry) Counter(name, help string, labels Labels) *Counter {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	f := r.family(name, help, typeCounter)
+	key := formatLabels(labels)
+	if s, ok := f.series[key]; ok {
+		c, ok := s.owner.(*Counter)
+		if !ok {
+			panic(fmt.Sprintf("metric %s%s already registered by CounterFunc", name, key))
+		}
+		return c
+	}
+	c := &Counter{}
+	f.series[key] = &series{labels: key, read: c.Value, owner: c}
+	return c
+}
+
+// Gauge 获取或创建gauge，名称与标签相同的指标已由GaugeFunc注册时panic
+func (r *Registry) Gauge(name, help string, labels Labels) *Gauge {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	f := r.family(name, help, typeGauge)
+	key := formatLabels(labels)
+	if s, ok := f.series[key]; ok {
+		g, ok := s.owner.(*Gauge)
+		if !ok {
+			panic(fmt.Sprintf("metric %s%s already registered by GaugeFunc", name, key))
+		}
+		return g
+	}
+	g := &Gauge{}
+	f.series[key] = &series{labels: key, read: g.Value, owner: g}
+	return g
+}
+
+func (r *Registry) registerFunc(name, help string, typ metricType, labels Labels, fn func() float64) (unregister func()) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	f := r.family(name, help, typ)
+	key := formatLabels(labels)
+	if old, ok := f.series[key]; ok && old.owner != nil {
+		panic(fmt.Sprintf("metric %s%s already registered by %T", name, key, old.owner))
+	}
+	s := &series{labels: key, read: fn}
+	f.series[key] = s
+	return func() {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		if f.series[key] == s {
+			delete(f.series, key)
+		}
+	}
+}
+
+// CounterFunc 输出时调用fn获取计数，返回移除该指标的函数。
+// 替换名称与标签相同的CounterFunc，已由Counter创建时panic
+func (r *Registry) CounterFunc(name, help string, labels Labels, fn func() float64) (unregister func()) {
+	return r.registerFunc(name, help, typeCounter, labels, fn)
+}
+
+// GaugeFunc 输出时调用fn获取当前值，返回移除该指标的函数。
+// 替换名称与标签相同的GaugeFunc，已由Gauge创建时panic
+func (r *Registry) GaugeFunc(name, help string, labels Labels, fn func() float64) (unregister func()) {
+	return r.registerFunc(name, help, typeGauge, labels, fn)
+}
+
+// Unregister 移除名称与标签对应的指标
+func (r *Registry) Unregister(name string, labels Labels) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if f, ok := r.families[name]; ok {
+		delete(f.series, formatLabels(labels))
+	}
+}
+
+func formatValue(f float64) string {
+	switch {
+	case math.IsInf(f, 1):
+		return "+Inf"
+	case math.IsInf(f, -1):
+		return "-Inf"
+	case math.IsNaN(f):
+		return "NaN"
+	default:
+		return strconv.FormatFloat(f, 'g', -1, 64)
+	}
+}
+
+var helpEscaper = strings.NewReplacer(`\`, `\\`, "\n", `\n`)
+
+// WriteText 以Prometheus文本格式(0.0.4)输出所有指标
+func (r *Registry) WriteText(w io.Writer) (err error) {
+	type snapshot struct {
+		name, help string
+		typ        metricType
+		series     []*series
+	}
+	r.mu.Lock()
+	families := make([]snapshot, 0, len(r.families))
+	for _, f := range r.families {
+		if len(f.series) == 0 {
+			continue
+		}
+		s := snapshot{name: f.name, help: f.help, typ: f.typ}
+		for _, item := range f.series {
+			s.series = append(s.series, item)
+		}
+		families = append(families, s)
+	}
+	r.mu.Unlock()
+
+	slices.SortFunc(families, func(a, b snapshot) int { return strings.Compare(a.name, b.name) })
+	var b strings.Builder
+	for _, f := range families {
+		slices.SortFunc(f.series, func(a, b *series) int { return strings.Compare(a.labels, b.labels) })
+		if f.help != "" {
+			fmt.Fprintf(&b, "# HELP %s %s\n", f.name, helpEscaper.Replace(f.help))
+		}
+		fmt.Fprintf(&b, "# TYPE %s %s\n", f.name, f.typ)
+		for _, s := range f.series {
+			fmt.Fprintf(&b, "%s%s %s\n", f.name, s.labels, formatValue(s.read()))
+		}
+	}
+	_, err = io.WriteString(w, b.String())
+	return
+}
+
+// ServeHTTP /metrics接口
+func (r *Registry) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	_ = r.WriteText(w)
+}
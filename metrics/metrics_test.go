@@ -0,0 +1,81 @@
+package metrics
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-compcont/compcont-core"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegistry(t *testing.T) {
+	r := NewRegistry()
+	c := r.Counter("requests_total", "Number of requests.", Labels{"path": "/a", "code": "200"})
+	c.Inc()
+	c.Add(2)
+	assert.Same(t, c, r.Counter("requests_total", "", Labels{"code": "200", "path": "/a"}))
+	r.Counter("requests_total", "", Labels{"path": `q"\`, "code": "500"}).Inc()
+	r.Gauge("temperature", "Line one\nline two.", nil).Set(-1.5)
+	unregister := r.GaugeFunc("answer", "", nil, func() float64 { return 42 })
+
+	assert.Panics(t, func() { r.Gauge("requests_total", "", nil) })
+	assert.Panics(t, func() { r.Counter("bad-name", "", nil) })
+	assert.Panics(t, func() { c.Add(-1) })
+	// 名称与标签相同时不能在func与普通指标之间替换
+	assert.Panics(t, func() { r.Gauge("answer", "", nil) })
+	assert.Panics(t, func() {
+		r.CounterFunc("requests_total", "", Labels{"path": "/a", "code": "200"}, func() float64 { return 0 })
+	})
+
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	assert.Equal(t, "text/plain; version=0.0.4; charset=utf-8", rec.Header().Get("Content-Type"))
+	assert.Equal(t, strings.Join([]string{
+		"# TYPE answer gauge",
+		"answer 42",
+		"# HELP requests_total Number of requests.",
+		"# TYPE requests_total counter",
+		`requests_total{code="200",path="/a"} 3`,
+		`requests_total{code="500",path="q\"\\"} 1`,
+		`# HELP temperature Line one\nline two.`,
+		"# TYPE temperature gauge",
+		"temperature -1.5",
+	}, "\n")+"\n", rec.Body.String())
+
+	unregister()
+	var b strings.Builder
+	assert.NoError(t, r.WriteText(&b))
+	assert.NotContains(t, b.String(), "answer")
+}
+
+func TestFactoryRegistry(t *testing.T) {
+	inner := compcont.NewFactoryRegistry()
+	compcont.MustRegister(inner, &compcont.TypedSimpleComponentFactory[struct{}, int]{
+		TypeID: "test.ok",
+		CreateInstanceFunc: func(ctx compcont.BuildContext, config struct{}) (int, error) {
+			return 1, nil
+		},
+	})
+	compcont.MustRegister(inner, &compcont.TypedSimpleComponentFactory[struct{}, int]{
+		TypeID: "test.fail",
+		CreateInstanceFunc: func(ctx compcont.BuildContext, config struct{}) (int, error) {
+			return 0, errors.New("fail")
+		},
+	})
+	r := NewRegistry()
+	cc := compcont.NewComponentContainer(compcont.WithFactoryRegistry(r.FactoryRegistry(inner)))
+	assert.NoError(t, cc.LoadNamedComponents([]compcont.ComponentConfig{
+		{Name: "a", Type: "test.ok"},
+	}))
+	assert.Error(t, cc.LoadNamedComponents([]compcont.ComponentConfig{
+		{Name: "b", Type: "test.fail"},
+	}))
+
+	var b strings.Builder
+	assert.NoError(t, r.WriteText(&b))
+	assert.Contains(t, b.String(), `compcont_component_build_duration_seconds{component="/a",type="test.ok"} `)
+	assert.Contains(t, b.String(), `compcont_component_build_failures_total{component="/b",type="test.fail"} 1`)
+}
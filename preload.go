@@ -5,5 +5,11 @@ import (
 	_ "github.com/go-compcont/compcont-std/compcont-zap"
 	_ "github.com/go-compcont/compcont-std/container"
 	_ "github.com/go-compcont/compcont-std/debug"
+	_ "github.com/go-compcont/compcont-std/http-middleware"
+	_ "github.com/go-compcont/compcont-std/http-resty"
+	_ "github.com/go-compcont/compcont-std/http-router"
+	_ "github.com/go-compcont/compcont-std/http-server"
+	_ "github.com/go-compcont/compcont-std/metrics"
 	_ "github.com/go-compcont/compcont-std/reloading"
+	_ "github.com/go-compcont/compcont-std/tracing"
 )
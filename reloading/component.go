@@ -2,6 +2,7 @@ package reloading
 
 import (
 	"github.com/go-compcont/compcont-core"
+	"github.com/go-compcont/compcont-std/container"
 	"github.com/go-resty/resty/v2"
 )
 
@@ -14,9 +15,20 @@ var factory compcont.IComponentFactory = &compcont.TypedSimpleComponentFactory[C
 		if cfg.Resty != nil {
 			restyClient = cfg.Resty.MustLoadComponent(ctx.Container).Instance
 		}
-		instance = NewReloading(cfg, restyClient)
+		var opts []Option
+		if cfg.Metrics != nil {
+			registry := cfg.Metrics.MustLoadComponent(ctx.Container).Instance
+			opts = append(opts, WithObserver(MetricsObserver(registry, container.Path(ctx.GetAbsolutePath()))))
+		}
+		if cfg.Tracing != nil {
+			opts = append(opts, WithTracerProvider(cfg.Tracing.MustLoadComponent(ctx.Container).Instance))
+		}
+		instance = NewReloading(cfg, restyClient, opts...)
 		return
 	},
+	DestroyInstanceFunc: func(ctx compcont.BuildContext, instance IReloading) (err error) {
+		return instance.Close()
+	},
 }
 
 func MustRegister(registry compcont.IFactoryRegistry) {
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"sync/atomic"
 
 	"github.com/go-compcont/compcont-core"
 	"gopkg.in/yaml.v3"
@@ -31,14 +32,17 @@ type IReloadingConfig[T any] interface {
 	LoadConfig(ctx context.Context) (T, error)
 	AddOnReloadingConfigListener(listener OnReloadingConfigListener[T]) int
 	RemoveOnReloadingConfigListener(id int)
+
+	// 移除自身注册的回调并关闭Reloading，KeepReloading时不关闭Reloading
 	Close() error
 }
 
 type ReloadingConfigOption[T any] struct {
-	Reloading    IReloading
-	StaticConfig *T
-	StructMode   bool
-	ConfigType   ConfigType
+	Reloading     IReloading
+	StaticConfig  *T
+	StructMode    bool
+	ConfigType    ConfigType
+	KeepReloading bool // Close时不关闭Reloading，用于引用其他组件的Reloading
 }
 
 func NewReloadingConfig[T any](opt ReloadingConfigOption[T]) IReloadingConfig[T] {
@@ -47,24 +51,31 @@ func NewReloadingConfig[T any](opt ReloadingConfigOption[T]) IReloadingConfig[T]
 	}
 
 	ret := &ReloadingConfig[T]{
-		staticConfig: opt.StaticConfig,
-		innerRaw:     opt.Reloading,
-		configType:   opt.ConfigType,
-		structMode:   opt.StructMode,
+		staticConfig:  opt.StaticConfig,
+		innerRaw:      opt.Reloading,
+		configType:    opt.ConfigType,
+		structMode:    opt.StructMode,
+		keepReloading: opt.KeepReloading,
+	}
+	// 仅配置static_config时没有Reloading
+	if opt.Reloading != nil {
+		ret.cacheListenerID = opt.Reloading.AddOnReloadingListener(OnReloadingListenerFunc(func(ctx context.Context, data []byte) error {
+			ret.currentConfig.Store(nil)
+			return nil
+		}))
 	}
-	opt.Reloading.AddOnReloadingListener(OnReloadingListenerFunc(func(ctx context.Context, data []byte) error {
-		ret.currentConfig = nil
-		return nil
-	}))
 	return ret
 }
 
 type ReloadingConfig[T any] struct {
 	staticConfig  *T
-	currentConfig *T // 如果当前有值，则直接返回，否则获取时重新反序列化
+	currentConfig atomic.Pointer[T] // 如果当前有值，则直接返回，否则获取时重新反序列化
 	configType    ConfigType
 	structMode    bool
 	innerRaw      IReloading
+	keepReloading bool
+
+	cacheListenerID int // 清除currentConfig的回调，Close时移除
 }
 
 func (r *ReloadingConfig[T]) LoadConfig(ctx context.Context) (cfg T, err error) {
@@ -72,8 +83,8 @@ func (r *ReloadingConfig[T]) LoadConfig(ctx context.Context) (cfg T, err error)
 		cfg = *r.staticConfig
 		return
 	}
-	if r.currentConfig != nil {
-		cfg = *r.currentConfig
+	if current := r.currentConfig.Load(); current != nil {
+		cfg = *current
 		return
 	}
 	currentConfigVal, err := r.unmarshal(r.innerRaw.Load(ctx))
@@ -140,6 +151,13 @@ func (r *ReloadingConfig[T]) RemoveOnReloadingConfigListener(id int) {
 }
 
 func (r *ReloadingConfig[T]) Close() error {
+	if r.innerRaw == nil {
+		return nil
+	}
+	r.innerRaw.RemoveOnReloadingListener(r.cacheListenerID)
+	if r.keepReloading {
+		return nil
+	}
 	return r.innerRaw.Close()
 }
 
@@ -160,6 +178,8 @@ func (r *ReloadingConfigConfig[T]) Build(cc compcont.IComponentContainer) (rc IR
 		StaticConfig: r.StaticConfig,
 		StructMode:   r.StructMode,
 		ConfigType:   r.ConfigType,
+		// 引用的Reloading由所在的容器管理，内联的随ReloadingConfig关闭
+		KeepReloading: r.Reloading != nil && r.Reloading.Refer != "",
 	})
 	return
 }
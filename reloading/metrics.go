@@ -0,0 +1,23 @@
+package reloading
+
+import (
+	"time"
+
+	"github.com/go-compcont/compcont-std/metrics"
+)
+
+// MetricsObserver 将加载结果记录到registry，name为reloading标签的值，通常为组件路径
+func MetricsObserver(r *metrics.Registry, name string) ReloadObserver {
+	labels := metrics.Labels{"reloading": name}
+	attempts := r.Counter("compcont_reloading_attempts_total", "Number of reload attempts.", labels)
+	failures := r.Counter("compcont_reloading_failures_total", "Number of failed reload attempts.", labels)
+	lastSuccess := r.Gauge("compcont_reloading_last_success_timestamp_seconds", "Unix time of the last successful reload.", labels)
+	return func(err error) {
+		attempts.Inc()
+		if err != nil {
+			failures.Inc()
+			return
+		}
+		lastSuccess.Set(float64(time.Now().UnixNano()) / 1e9)
+	}
+}
@@ -9,11 +9,17 @@ import (
 	"fmt"
 	"log/slog"
 	"os"
+	"slices"
 	"sync"
 	"time"
 
 	"github.com/go-compcont/compcont-core"
+	"github.com/go-compcont/compcont-std/metrics"
 	"github.com/go-resty/resty/v2"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+	"go.opentelemetry.io/otel/trace/noop"
 )
 
 type Config struct {
@@ -22,6 +28,29 @@ type Config struct {
 	LocalFile         string                                             `ccf:"local_file"`         // 保存到本地文件配置
 	ReloadingDuration time.Duration                                      `ccf:"reloading_duration"` // 设为0表示不会定时reload配置
 	Resty             *compcont.TypedComponentConfig[any, *resty.Client] `ccf:"resty"`              // 配置加载使用的resty，不填使用默认resty
+
+	// 输出加载次数、失败次数与最近成功时间的std.metrics组件(可选)
+	Metrics *compcont.TypedComponentConfig[any, *metrics.Registry] `ccf:"metrics"`
+
+	// 引用std.tracing时每次加载创建span，远程加载的请求在其之下(可选)
+	Tracing *compcont.TypedComponentConfig[any, trace.TracerProvider] `ccf:"tracing"`
+}
+
+// 每次加载(包括首次加载)结束后回调，err为nil表示加载成功
+type ReloadObserver func(err error)
+
+type Option func(r *Reloading)
+
+func WithObserver(observer ReloadObserver) Option {
+	return func(r *Reloading) {
+		r.observers = append(r.observers, observer)
+	}
+}
+
+func WithTracerProvider(tp trace.TracerProvider) Option {
+	return func(r *Reloading) {
+		r.tracer = tp.Tracer("github.com/go-compcont/compcont-std/reloading")
+	}
 }
 
 type OnReloadingListener interface {
@@ -38,7 +67,7 @@ type IReloading interface {
 	// 加载当前数据
 	Load(ctx context.Context) []byte
 
-	// 配置变更回调
+	// 配置变更回调，返回的id在移除前保持不变
 	AddOnReloadingListener(listener OnReloadingListener) int
 
 	// 移除回调
@@ -48,19 +77,30 @@ type IReloading interface {
 	Close() error
 }
 
+type reloadingListener struct {
+	id       int
+	listener OnReloadingListener
+}
+
 type Reloading struct {
 	Config
-	ticker    *time.Ticker
-	resty     *resty.Client
-	listeners []OnReloadingListener
+	ticker *time.Ticker
+	resty  *resty.Client
+
+	observers []ReloadObserver
+	tracer    trace.Tracer
+
+	reloadMu sync.Mutex // 串行执行加载，保护md5sum
+	md5sum   []byte
 
+	mu         sync.Mutex // 保护listeners与data，调用回调时不持有，回调中可以增删回调
+	listeners  []reloadingListener
+	nextID     int
 	data       []byte
-	md5sum     []byte
-	mu         sync.Mutex
 	cancelFunc context.CancelFunc
 }
 
-func NewReloading(cfg Config, resty *resty.Client) IReloading {
+func NewReloading(cfg Config, client *resty.Client, opts ...Option) IReloading {
 	if cfg.StaticData != "" {
 		return &Reloading{
 			Config: cfg,
@@ -69,6 +109,9 @@ func NewReloading(cfg Config, resty *resty.Client) IReloading {
 	if cfg.LocalFile == "" {
 		panic("")
 	}
+	if client == nil && cfg.RemoteURL != "" { // 未指定resty时使用默认resty
+		client = resty.New()
+	}
 	var ticker *time.Ticker
 	if cfg.ReloadingDuration != 0 {
 		ticker = time.NewTicker(cfg.ReloadingDuration)
@@ -78,9 +121,13 @@ func NewReloading(cfg Config, resty *resty.Client) IReloading {
 	ret := &Reloading{
 		Config:     cfg,
 		ticker:     ticker,
-		resty:      resty,
+		resty:      client,
+		tracer:     noop.NewTracerProvider().Tracer(""),
 		cancelFunc: cancelFunc,
 	}
+	for _, opt := range opts {
+		opt(ret)
+	}
 	err := ret.startReloading(ctx)
 	if err != nil {
 		panic(err)
@@ -97,7 +144,7 @@ func calcMD5checksum(b []byte) []byte {
 func (c *Reloading) startReloading(ctx context.Context) (err error) {
 	// 首次启动时先主动获取一次数据
 	slog.Debug("first reload")
-	c.data, err = c.reload(ctx)
+	err = c.reloadData(ctx)
 	if err != nil {
 		slog.Error("first reload error", slog.Any("error", err))
 		return
@@ -109,8 +156,7 @@ func (c *Reloading) startReloading(ctx context.Context) (err error) {
 			for {
 				select {
 				case <-c.ticker.C:
-					c.data, err = c.reload(context.Background())
-					if err != nil {
+					if err := c.reloadData(context.Background()); err != nil {
 						slog.Error("reload error", slog.Any("error", err))
 					}
 				case <-ctx.Done():
@@ -130,10 +176,14 @@ func (c *Reloading) remoteReload(ctx context.Context) (data []byte, err error) {
 		slog.Error("fetch remote data error", slog.Any("error", err))
 		return
 	}
+	if resp.IsError() {
+		err = fmt.Errorf("fetch remote data: unexpected status %s", resp.Status())
+		return
+	}
 	data = resp.Body()
 
 	md5sum := calcMD5checksum(data)
-	if !bytes.Equal(md5sum, c.md5sum) {
+	if bytes.Equal(md5sum, c.md5sum) {
 		return
 	}
 
@@ -198,12 +248,34 @@ func (c *Reloading) localReload(ctx context.Context) (data []byte, err error) {
 	return
 }
 
-func (c *Reloading) reload(ctx context.Context) (data []byte, err error) {
+func (c *Reloading) reloadData(ctx context.Context) (err error) {
+	data, err := c.reload(ctx)
 	c.mu.Lock()
 	defer c.mu.Unlock()
+	c.data = data
+	return
+}
+
+func (c *Reloading) reload(ctx context.Context) (data []byte, err error) {
+	c.reloadMu.Lock()
+	defer c.reloadMu.Unlock()
+	ctx, span := c.tracer.Start(ctx, "reloading.reload", trace.WithAttributes(
+		attribute.String("reloading.local_file", c.LocalFile),
+		attribute.String("reloading.remote_url", c.RemoteURL),
+	))
+	defer func() {
+		if err != nil {
+			span.RecordError(err)
+			span.SetStatus(codes.Error, err.Error())
+		}
+		span.End()
+		for _, observe := range c.observers {
+			observe(err)
+		}
+	}()
 
 	// 设置了client且设置了远程地址
-	if c.resty == nil && c.RemoteURL != "" {
+	if c.resty != nil && c.RemoteURL != "" {
 		// 尝试加载远程地址并保存到本地
 		data, err = c.remoteReload(ctx)
 		if err == nil {
@@ -223,8 +295,12 @@ func (c *Reloading) reload(ctx context.Context) (data []byte, err error) {
 }
 
 func (c *Reloading) onReloading(ctx context.Context, data []byte) (err error) {
-	for _, listener := range c.listeners {
-		err = listener.OnReloading(ctx, data)
+	// 回调可能会获取其他锁(如关闭logger时移除回调)，复制一份后在锁外调用
+	c.mu.Lock()
+	listeners := slices.Clone(c.listeners)
+	c.mu.Unlock()
+	for _, l := range listeners {
+		err = l.listener.OnReloading(ctx, data)
 		if err != nil {
 			return
 		}
@@ -237,6 +313,8 @@ func (c *Reloading) Load(ctx context.Context) (data []byte) {
 		data = []byte(c.Config.StaticData)
 		return
 	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
 	return c.data
 }
 
@@ -244,19 +322,18 @@ func (c *Reloading) AddOnReloadingListener(listener OnReloadingListener) int {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
-	c.listeners = append(c.listeners, listener)
-	return len(c.listeners) - 1
+	c.nextID++
+	c.listeners = append(c.listeners, reloadingListener{id: c.nextID, listener: listener})
+	return c.nextID
 }
 
 func (c *Reloading) RemoveOnReloadingListener(id int) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
-	if id < 0 || id >= len(c.listeners) {
-		return
-	}
-
-	c.listeners = append(c.listeners[:id], c.listeners[id+1:]...)
+	c.listeners = slices.DeleteFunc(c.listeners, func(l reloadingListener) bool {
+		return l.id == id
+	})
 }
 
 func (c *Reloading) Close() error {
@@ -266,7 +343,9 @@ func (c *Reloading) Close() error {
 	if c.ticker != nil {
 		c.ticker.Stop()
 	}
-	c.cancelFunc()
+	if c.cancelFunc != nil { // static_data时没有启动加载
+		c.cancelFunc()
+	}
 	c.listeners = nil
 	return nil
 }
@@ -0,0 +1,209 @@
+package reloading
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/go-compcont/compcont-core"
+	"github.com/go-resty/resty/v2"
+	"github.com/stretchr/testify/assert"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func newFileReloading(t *testing.T, data string) (r *Reloading, file string) {
+	file = filepath.Join(t.TempDir(), "config.yaml")
+	if !assert.NoError(t, os.WriteFile(file, []byte(data), 0666)) {
+		return
+	}
+	r = NewReloading(Config{LocalFile: file}, nil).(*Reloading)
+	return
+}
+
+func TestListenerIDStable(t *testing.T) {
+	r, file := newFileReloading(t, "a")
+	if r == nil {
+		return
+	}
+	defer r.Close()
+
+	var called []string
+	listener := func(name string) OnReloadingListener {
+		return OnReloadingListenerFunc(func(ctx context.Context, data []byte) error {
+			called = append(called, name)
+			return nil
+		})
+	}
+	first := r.AddOnReloadingListener(listener("first"))
+	second := r.AddOnReloadingListener(listener("second"))
+	third := r.AddOnReloadingListener(listener("third"))
+
+	// 移除前面的回调后，后面回调的id仍然有效
+	r.RemoveOnReloadingListener(first)
+	r.RemoveOnReloadingListener(third)
+	assert.NoError(t, os.WriteFile(file, []byte("b"), 0666))
+	assert.NoError(t, r.reloadData(context.Background()))
+	assert.Equal(t, []string{"second"}, called)
+	assert.Equal(t, []byte("b"), r.Load(context.Background()))
+
+	r.RemoveOnReloadingListener(second)
+	assert.NoError(t, os.WriteFile(file, []byte("c"), 0666))
+	assert.NoError(t, r.reloadData(context.Background()))
+	assert.Equal(t, []string{"second"}, called)
+}
+
+func TestListenerRemoveDuringReload(t *testing.T) {
+	r, file := newFileReloading(t, "a")
+	if r == nil {
+		return
+	}
+	defer r.Close()
+
+	// 回调期间不持有Reloading的锁，回调中可以移除回调
+	var id int
+	id = r.AddOnReloadingListener(OnReloadingListenerFunc(func(ctx context.Context, data []byte) error {
+		r.RemoveOnReloadingListener(id)
+		return nil
+	}))
+	assert.NoError(t, os.WriteFile(file, []byte("b"), 0666))
+
+	reloaded := make(chan error, 1)
+	go func() {
+		reloaded <- r.reloadData(context.Background())
+	}()
+	select {
+	case err := <-reloaded:
+		assert.NoError(t, err)
+		assert.Empty(t, r.listeners)
+	case <-time.After(5 * time.Second):
+		assert.Fail(t, "reload deadlocked")
+	}
+}
+
+func TestReloadingConfigCloseRemovesListener(t *testing.T) {
+	r, _ := newFileReloading(t, "key: value")
+	if r == nil {
+		return
+	}
+	defer r.Close()
+
+	rc := NewReloadingConfig(ReloadingConfigOption[map[string]string]{Reloading: r, KeepReloading: true})
+	cfg, err := rc.LoadConfig(context.Background())
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, map[string]string{"key": "value"}, cfg)
+	assert.Len(t, r.listeners, 1)
+
+	assert.NoError(t, rc.Close())
+	assert.Empty(t, r.listeners)
+}
+
+func TestStaticReloadingClose(t *testing.T) {
+	r := NewReloading(Config{StaticData: "static"}, nil)
+	assert.Equal(t, []byte("static"), r.Load(context.Background()))
+	assert.NotPanics(t, func() {
+		assert.NoError(t, r.Close())
+	})
+}
+
+// 记录是否被关闭的IReloading
+type fakeReloading struct {
+	*Reloading
+	closed bool
+}
+
+func (f *fakeReloading) Close() error {
+	f.closed = true
+	return f.Reloading.Close()
+}
+
+func TestReloadingConfigBuildKeepReloading(t *testing.T) {
+	var created []*fakeReloading
+	registry := compcont.NewFactoryRegistry()
+	compcont.MustRegister(registry, &compcont.TypedSimpleComponentFactory[struct{}, IReloading]{
+		TypeID: "test.reloading",
+		CreateInstanceFunc: func(ctx compcont.BuildContext, config struct{}) (IReloading, error) {
+			f := &fakeReloading{Reloading: NewReloading(Config{StaticData: "key: value"}, nil).(*Reloading)}
+			created = append(created, f)
+			return f, nil
+		},
+	})
+	cc := compcont.NewComponentContainer(compcont.WithFactoryRegistry(registry))
+	if !assert.NoError(t, cc.LoadNamedComponents([]compcont.ComponentConfig{{Name: "shared", Type: "test.reloading"}})) {
+		return
+	}
+
+	// 引用的Reloading可能被多个组件共享，由所在的容器销毁，关闭ReloadingConfig时不能关闭
+	shared := &ReloadingConfigConfig[map[string]string]{Reloading: &compcont.TypedComponentConfig[any, IReloading]{Refer: "shared"}}
+	rc, err := shared.Build(cc)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.NoError(t, rc.Close())
+	// 内联的Reloading只属于该ReloadingConfig，随其关闭
+	inline := &ReloadingConfigConfig[map[string]string]{Reloading: &compcont.TypedComponentConfig[any, IReloading]{Type: "test.reloading"}}
+	rc, err = inline.Build(cc)
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.NoError(t, rc.Close())
+
+	if !assert.Len(t, created, 2) {
+		return
+	}
+	assert.False(t, created[0].closed)
+	assert.Empty(t, created[0].listeners)
+	assert.True(t, created[1].closed)
+}
+
+// 在请求的context下创建client span，与tracing.Transport相同
+type spanTransport struct {
+	tracer trace.Tracer
+}
+
+func (t *spanTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx, span := t.tracer.Start(req.Context(), "HTTP "+req.Method, trace.WithSpanKind(trace.SpanKindClient))
+	defer span.End()
+	return http.DefaultTransport.RoundTrip(req.WithContext(ctx))
+}
+
+func TestRemoteReloadTracing(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("remote"))
+	}))
+	defer srv.Close()
+	file := filepath.Join(t.TempDir(), "config.yaml")
+	if !assert.NoError(t, os.WriteFile(file, []byte("local"), 0666)) {
+		return
+	}
+	exporter := tracetest.NewInMemoryExporter()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	defer tp.Shutdown(context.Background())
+
+	client := resty.New().SetTransport(&spanTransport{tracer: tp.Tracer("test")})
+	r := NewReloading(Config{RemoteURL: srv.URL, LocalFile: file}, client, WithTracerProvider(tp))
+	defer r.Close()
+
+	// 远程加载成功时使用远程数据并保存到本地文件
+	assert.Equal(t, []byte("remote"), r.Load(context.Background()))
+	saved, err := os.ReadFile(file)
+	assert.NoError(t, err)
+	assert.Equal(t, "remote", string(saved))
+
+	spans := exporter.GetSpans()
+	if !assert.Len(t, spans, 2) {
+		return
+	}
+	request, reload := spans[0], spans[1]
+	assert.Equal(t, "HTTP GET", request.Name)
+	assert.Equal(t, "reloading.reload", reload.Name)
+	assert.Equal(t, reload.SpanContext.TraceID(), request.SpanContext.TraceID())
+	assert.Equal(t, reload.SpanContext.SpanID(), request.Parent.SpanID())
+}
@@ -0,0 +1,347 @@
+package schema
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/go-compcont/compcont-core"
+)
+
+const Draft = "https://json-schema.org/draft/2020-12/schema"
+
+// 组件配置列表中单个组件的定义名称
+const ComponentDefName = "component"
+
+// JSON Schema的子集，仅包含生成器会用到的关键字
+type Schema struct {
+	Schema               string             `json:"$schema,omitempty"`
+	ID                   string             `json:"$id,omitempty"`
+	Ref                  string             `json:"$ref,omitempty"`
+	Title                string             `json:"title,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Pattern              string             `json:"pattern,omitempty"`
+	Minimum              *float64           `json:"minimum,omitempty"`
+	Const                any                `json:"const,omitempty"`
+	Enum                 []any              `json:"enum,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AdditionalProperties any                `json:"additionalProperties,omitempty"` // bool或*Schema
+	Items                *Schema            `json:"items,omitempty"`
+	AnyOf                []*Schema          `json:"anyOf,omitempty"`
+	AllOf                []*Schema          `json:"allOf,omitempty"`
+	If                   *Schema            `json:"if,omitempty"`
+	Then                 *Schema            `json:"then,omitempty"`
+	Defs                 map[string]*Schema `json:"$defs,omitempty"`
+}
+
+func (s *Schema) MarshalIndent() ([]byte, error) {
+	return json.MarshalIndent(s, "", "  ")
+}
+
+func refTo(name string) *Schema {
+	return &Schema{Ref: "#/$defs/" + name}
+}
+
+// 非TypedSimpleComponentFactory实现的工厂可以通过实现该接口声明自己的配置类型
+type IConfigTypeProvider interface {
+	ConfigType() reflect.Type
+}
+
+// ConfigTypeOf 获取组件工厂的配置类型，
+// TypedSimpleComponentFactory通过CreateInstanceFunc的第二个入参推断
+func ConfigTypeOf(f compcont.IComponentFactory) (t reflect.Type, ok bool) {
+	if p, isProvider := f.(IConfigTypeProvider); isProvider {
+		t = p.ConfigType()
+		ok = t != nil
+		return
+	}
+	v := reflect.ValueOf(f)
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return
+		}
+		v = v.Elem()
+	}
+	if v.Kind() != reflect.Struct {
+		return
+	}
+	fn := v.FieldByName("CreateInstanceFunc")
+	if !fn.IsValid() || fn.Kind() != reflect.Func || fn.Type().NumIn() != 2 {
+		return
+	}
+	t = fn.Type().In(1)
+	ok = true
+	return
+}
+
+var (
+	componentConfigType = reflect.TypeOf(compcont.ComponentConfig{})
+	durationType        = reflect.TypeOf(time.Duration(0))
+	timeType            = reflect.TypeOf(time.Time{})
+)
+
+// IsComponentConfig 判断类型是否为组件配置(ComponentConfig或TypedComponentConfig)，
+// 若为TypedComponentConfig，同时返回其Config字段的类型
+func IsComponentConfig(t reflect.Type) (ok bool, configType reflect.Type) {
+	if t == componentConfigType {
+		return true, nil
+	}
+	if t.Kind() != reflect.Struct || t.PkgPath() != componentConfigType.PkgPath() {
+		return
+	}
+	if !strings.HasPrefix(t.Name(), "TypedComponentConfig[") {
+		return
+	}
+	f, _ := t.FieldByName("Config")
+	return true, f.Type
+}
+
+// FieldKey 返回结构体字段在配置中对应的key，与组件配置解码时的ccf标签规则一致
+func FieldKey(f reflect.StructField) (key string, squash bool, skip bool) {
+	if !f.IsExported() {
+		skip = true
+		return
+	}
+	tag := f.Tag.Get(compcont.ConfigFieldTagName)
+	if tag == "-" {
+		skip = true
+		return
+	}
+	parts := strings.Split(tag, ",")
+	key = parts[0]
+	squash = slices.Contains(parts[1:], "squash")
+	if key == "" {
+		key = f.Name
+	}
+	return
+}
+
+var (
+	pkgPathRegexp   = regexp.MustCompile(`[\w.\-]+/`)
+	defNameSanitize = regexp.MustCompile(`[^A-Za-z0-9_.\-]+`)
+)
+
+func defName(t reflect.Type) string {
+	name := pkgPathRegexp.ReplaceAllString(t.String(), "")
+	return strings.Trim(defNameSanitize.ReplaceAllString(name, "_"), "_")
+}
+
+func configDefName(typeID compcont.ComponentTypeID) string {
+	return "config." + string(typeID)
+}
+
+type Generator struct {
+	registry compcont.IFactoryRegistry
+	defs     map[string]*Schema
+}
+
+func NewGenerator(registry compcont.IFactoryRegistry) *Generator {
+	return &Generator{registry: registry}
+}
+
+func (g *Generator) sortedTypes() []compcont.ComponentTypeID {
+	types := g.registry.RegisteredComponentTypes()
+	slices.Sort(types)
+	return types
+}
+
+func (g *Generator) configSchema(typeID compcont.ComponentTypeID) (s *Schema, err error) {
+	f, err := g.registry.GetFactory(typeID)
+	if err != nil {
+		return
+	}
+	t, ok := ConfigTypeOf(f)
+	if !ok {
+		s = &Schema{} // 无法推断配置类型，接受任意配置
+		return
+	}
+	s = g.typeSchema(t)
+	return
+}
+
+func (g *Generator) typeSchema(t reflect.Type) *Schema {
+	switch t {
+	case durationType:
+		return &Schema{AnyOf: []*Schema{
+			{Type: "string", Pattern: `^([-+]?([0-9]*(\.[0-9]*)?[a-zµ]+)+|0)$`},
+			{Type: "integer"},
+		}}
+	case timeType:
+		return &Schema{Type: "string", Format: "date-time"}
+	}
+	if ok, cfgType := IsComponentConfig(t); ok {
+		if cfgType == nil || cfgType.Kind() == reflect.Interface {
+			return refTo(ComponentDefName)
+		}
+		return &Schema{AllOf: []*Schema{
+			refTo(ComponentDefName),
+			{Properties: map[string]*Schema{"config": g.typeSchema(cfgType)}},
+		}}
+	}
+
+	switch t.Kind() {
+	case reflect.Pointer:
+		return g.typeSchema(t.Elem())
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return &Schema{Type: "integer"}
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		zero := 0.0
+		return &Schema{Type: "integer", Minimum: &zero}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		return &Schema{Type: "array", Items: g.typeSchema(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: g.typeSchema(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return g.structSchema(t)
+		}
+		// 具名结构体放入$defs，避免递归类型无限展开
+		name := defName(t)
+		if _, ok := g.defs[name]; !ok {
+			g.defs[name] = nil // 占位，防止递归
+			g.defs[name] = g.structSchema(t)
+		}
+		return refTo(name)
+	default: // interface、func等
+		return &Schema{}
+	}
+}
+
+func (g *Generator) structSchema(t reflect.Type) *Schema {
+	s := &Schema{
+		Type:                 "object",
+		Properties:           map[string]*Schema{},
+		AdditionalProperties: false,
+	}
+	if t.Name() != "" {
+		s.Title = defName(t)
+	}
+	g.collectFields(t, s)
+	return s
+}
+
+func (g *Generator) collectFields(t reflect.Type, s *Schema) {
+	for i := range t.NumField() {
+		f := t.Field(i)
+		key, squash, skip := FieldKey(f)
+		if skip {
+			continue
+		}
+		if squash {
+			ft := f.Type
+			if ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				g.collectFields(ft, s)
+				continue
+			}
+		}
+		s.Properties[key] = g.typeSchema(f.Type)
+	}
+}
+
+// 组件配置项自身的结构，config的约束由调用方决定
+func componentSchema(types []compcont.ComponentTypeID) *Schema {
+	typeEnum := make([]any, 0, len(types))
+	for _, t := range types {
+		typeEnum = append(typeEnum, string(t))
+	}
+	namePattern := `^[a-zA-Z_][a-zA-Z0-9_]*$`
+	return &Schema{
+		Type: "object",
+		Properties: map[string]*Schema{
+			"name":   {Type: "string", Pattern: namePattern},
+			"type":   {Type: "string", Enum: typeEnum},
+			"refer":  {Type: "string"},
+			"deps":   {Type: "array", Items: &Schema{Type: "string", Pattern: namePattern}},
+			"config": {},
+		},
+		AdditionalProperties: false,
+		AnyOf: []*Schema{
+			{Required: []string{"type"}},
+			{Required: []string{"refer"}},
+		},
+	}
+}
+
+// TypeSchema 生成单个组件类型的配置Schema
+func (g *Generator) TypeSchema(typeID compcont.ComponentTypeID) (s *Schema, err error) {
+	g.defs = map[string]*Schema{}
+	cfg, err := g.configSchema(typeID)
+	if err != nil {
+		return
+	}
+	g.defs[ComponentDefName] = componentSchema(g.sortedTypes())
+	s = cfg
+	if s.Ref != "" || s.AllOf != nil || s.AnyOf != nil {
+		s = &Schema{AllOf: []*Schema{cfg}}
+	}
+	s.Schema = Draft
+	s.Title = string(typeID)
+	s.Defs = g.defs
+	return
+}
+
+// TypeSchemas 生成注册器中所有组件类型的配置Schema
+func (g *Generator) TypeSchemas() (schemas map[compcont.ComponentTypeID]*Schema, err error) {
+	schemas = map[compcont.ComponentTypeID]*Schema{}
+	for _, t := range g.sortedTypes() {
+		var s *Schema
+		s, err = g.TypeSchema(t)
+		if err != nil {
+			err = fmt.Errorf("generate schema for %s: %w", t, err)
+			return
+		}
+		schemas[t] = s
+	}
+	return
+}
+
+// ComponentListSchema 生成组件配置列表([]ComponentConfig)的Schema，
+// 每个组件的config按照其type对应的配置Schema校验，嵌套容器中的组件同样生效
+func (g *Generator) ComponentListSchema() (s *Schema, err error) {
+	g.defs = map[string]*Schema{}
+	types := g.sortedTypes()
+	component := componentSchema(types)
+	for _, t := range types {
+		var cfg *Schema
+		cfg, err = g.configSchema(t)
+		if err != nil {
+			err = fmt.Errorf("generate schema for %s: %w", t, err)
+			return
+		}
+		g.defs[configDefName(t)] = cfg
+		component.AllOf = append(component.AllOf, &Schema{
+			If: &Schema{
+				Properties: map[string]*Schema{"type": {Const: string(t)}},
+				Required:   []string{"type"},
+			},
+			Then: &Schema{
+				Properties: map[string]*Schema{"config": refTo(configDefName(t))},
+			},
+		})
+	}
+	g.defs[ComponentDefName] = component
+	s = &Schema{
+		Schema: Draft,
+		Title:  "compcont component list",
+		Type:   "array",
+		Items:  refTo(ComponentDefName),
+		Defs:   g.defs,
+	}
+	return
+}
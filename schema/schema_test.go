@@ -0,0 +1,63 @@
+package schema
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/go-compcont/compcont-core"
+	"github.com/stretchr/testify/assert"
+)
+
+type testConfig struct {
+	Name     string                                      `ccf:"name"`
+	Duration time.Duration                               `ccf:"duration"`
+	Tags     map[string]string                           `ccf:"tags"`
+	Inner    *compcont.TypedComponentConfig[any, string] `ccf:"inner"`
+	Self     *testConfig                                 `ccf:"self"`
+	Ignored  string                                      `ccf:"-"`
+}
+
+var testFactory = &compcont.TypedSimpleComponentFactory[testConfig, any]{
+	TypeID: "test",
+	CreateInstanceFunc: func(ctx compcont.BuildContext, config testConfig) (instance any, err error) {
+		return
+	},
+}
+
+var echoFactory = &compcont.TypedSimpleComponentFactory[string, any]{
+	TypeID: "echo",
+	CreateInstanceFunc: func(ctx compcont.BuildContext, config string) (instance any, err error) {
+		return
+	},
+}
+
+func TestGenerator(t *testing.T) {
+	registry := compcont.NewFactoryRegistry()
+	compcont.MustRegister(registry, testFactory)
+	compcont.MustRegister(registry, echoFactory)
+	g := NewGenerator(registry)
+
+	s, err := g.TypeSchema("test")
+	assert.NoError(t, err)
+	def := s.Defs["schema.testConfig"]
+	if assert.NotNil(t, def) {
+		assert.Equal(t, false, def.AdditionalProperties)
+		assert.Contains(t, def.Properties, "duration")
+		assert.NotContains(t, def.Properties, "Ignored")
+		assert.Equal(t, "#/$defs/schema.testConfig", def.Properties["self"].Ref)
+		assert.Equal(t, "#/$defs/component", def.Properties["inner"].Ref)
+	}
+
+	s, err = g.TypeSchema("echo")
+	assert.NoError(t, err)
+	assert.Equal(t, "string", s.Type)
+
+	list, err := g.ComponentListSchema()
+	assert.NoError(t, err)
+	assert.Equal(t, "array", list.Type)
+	assert.Contains(t, list.Defs, "config.echo")
+	assert.Len(t, list.Defs[ComponentDefName].AllOf, 2)
+	_, err = json.Marshal(list)
+	assert.NoError(t, err)
+}
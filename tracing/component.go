@@ -0,0 +1,58 @@
+package tracing
+
+import (
+	"context"
+	"time"
+
+	"github.com/go-compcont/compcont-core"
+	compcontzap "github.com/go-compcont/compcont-std/compcont-zap"
+	"go.opentelemetry.io/otel"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+const TypeID compcont.ComponentTypeID = "std.tracing"
+
+// 实例为trace.TracerProvider，可被std.http.resty、std.http.server与std.reloading的tracing引用
+var factory compcont.IComponentFactory = &compcont.TypedSimpleComponentFactory[Config, trace.TracerProvider]{
+	TypeID: TypeID,
+	CreateInstanceFunc: func(ctx compcont.BuildContext, cfg Config) (instance trace.TracerProvider, err error) {
+		tp, err := New(cfg)
+		if err != nil {
+			return
+		}
+		if cfg.Global {
+			otel.SetTracerProvider(tp)
+			otel.SetTextMapPropagator(Propagator)
+		}
+		instance = tp
+		return
+	},
+	DestroyInstanceFunc: func(ctx compcont.BuildContext, instance trace.TracerProvider) (err error) {
+		// 导出剩余的span
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		return instance.(*sdktrace.TracerProvider).Shutdown(shutdownCtx)
+	},
+}
+
+func MustRegister(registry compcont.IFactoryRegistry) {
+	compcont.MustRegister(registry, factory)
+}
+
+func init() {
+	MustRegister(compcont.DefaultFactoryRegistry)
+
+	// context中有span时为FromContext获取的logger加上trace_id与span_id，已通过WithTraceIDs保存时以其为准
+	compcontzap.RegisterFieldExtractor(func(ctx context.Context) []zap.Field {
+		if _, _, ok := compcontzap.TraceIDsFromContext(ctx); ok {
+			return nil
+		}
+		sc := trace.SpanContextFromContext(ctx)
+		if !sc.IsValid() {
+			return nil
+		}
+		return []zap.Field{zap.String("trace_id", sc.TraceID().String()), zap.String("span_id", sc.SpanID().String())}
+	})
+}
@@ -0,0 +1,126 @@
+package tracing
+
+import (
+	"net/http"
+	"strconv"
+
+	compcontzap "github.com/go-compcont/compcont-std/compcont-zap"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const instrumentationName = "github.com/go-compcont/compcont-std/tracing"
+
+// HTTP请求的上下文传播使用W3C traceparent与baggage
+var Propagator propagation.TextMapPropagator = propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{})
+
+type transport struct {
+	base   http.RoundTripper
+	tracer trace.Tracer
+}
+
+// Transport 为每个请求创建client span并注入traceparent，base为nil时使用http.DefaultTransport
+func Transport(tp trace.TracerProvider, base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return &transport{base: base, tracer: tp.Tracer(instrumentationName)}
+}
+
+func (t *transport) RoundTrip(r *http.Request) (resp *http.Response, err error) {
+	ctx, span := t.tracer.Start(r.Context(), "HTTP "+r.Method,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("http.request.method", r.Method),
+			attribute.String("url.full", r.URL.Redacted()),
+			attribute.String("server.address", r.URL.Hostname()),
+		),
+	)
+	defer span.End()
+
+	r = r.Clone(ctx)
+	Propagator.Inject(ctx, propagation.HeaderCarrier(r.Header))
+	resp, err = t.base.RoundTrip(r)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+		return
+	}
+	span.SetAttributes(attribute.Int("http.response.status_code", resp.StatusCode))
+	if resp.StatusCode >= 400 {
+		span.SetStatus(codes.Error, strconv.Itoa(resp.StatusCode))
+	}
+	return
+}
+
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (w *statusRecorder) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *statusRecorder) Write(p []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	return w.ResponseWriter.Write(p)
+}
+
+// 直接断言http.Flusher的handler(如SSE)依赖该方法
+func (w *statusRecorder) Flush() {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	_ = http.NewResponseController(w.ResponseWriter).Flush()
+}
+
+func (w *statusRecorder) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// Middleware 从请求头中提取上游的trace并为每个请求创建server span，
+// span的trace ID与span ID同时通过compcontzap.WithTraceIDs保存，FromContext获取的logger会带上
+func Middleware(tp trace.TracerProvider) func(http.Handler) http.Handler {
+	tracer := tp.Tracer(instrumentationName)
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := Propagator.Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+			ctx, span := tracer.Start(ctx, "HTTP "+r.Method,
+				trace.WithSpanKind(trace.SpanKindServer),
+				trace.WithAttributes(
+					attribute.String("http.request.method", r.Method),
+					attribute.String("url.path", r.URL.Path),
+					attribute.String("client.address", r.RemoteAddr),
+				),
+			)
+			defer span.End()
+			if sc := span.SpanContext(); sc.IsValid() {
+				ctx = compcontzap.WithTraceIDs(ctx, sc.TraceID().String(), sc.SpanID().String())
+			}
+
+			rec := &statusRecorder{ResponseWriter: w}
+			r = r.WithContext(ctx)
+			next.ServeHTTP(rec, r)
+
+			if r.Pattern != "" { // 由http.ServeMux匹配到的路由
+				span.SetName(r.Pattern)
+			}
+			status := rec.status
+			if status == 0 {
+				status = http.StatusOK
+			}
+			span.SetAttributes(attribute.Int("http.response.status_code", status))
+			if status >= 500 {
+				span.SetStatus(codes.Error, strconv.Itoa(status))
+			}
+		})
+	}
+}
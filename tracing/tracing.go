@@ -0,0 +1,201 @@
+package tracing
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+const (
+	ExporterStdout = "stdout" // JSON输出到标准输出
+	ExporterFile   = "file"   // JSON追加写入文件
+	ExporterOTLP   = "otlp"   // OTLP/HTTP
+)
+
+type ExporterConfig struct {
+	Type        string `ccf:"type"`         // stdout、file、otlp
+	PrettyPrint bool   `ccf:"pretty_print"` // stdout、file: 格式化输出JSON
+	Path        string `ccf:"path"`         // file: 文件路径
+
+	// otlp: 例如"http://localhost:4318"(默认路径/v1/traces)或"localhost:4318"(HTTPS)
+	Endpoint    string            `ccf:"endpoint"`
+	Insecure    bool              `ccf:"insecure"`    // otlp: endpoint不带scheme时使用HTTP
+	Headers     map[string]string `ccf:"headers"`     // otlp
+	Compression string            `ccf:"compression"` // otlp: ""或"gzip"
+	Timeout     time.Duration     `ccf:"timeout"`     // otlp: 单次导出超时，默认10s
+}
+
+type BatchConfig struct {
+	Disabled           bool          `ccf:"disabled"`              // 每个span结束时同步导出，用于调试
+	Timeout            time.Duration `ccf:"timeout"`               // 批量导出的最长等待时间，默认5s
+	MaxQueueSize       int           `ccf:"max_queue_size"`        // 默认2048，队列满时丢弃
+	MaxExportBatchSize int           `ccf:"max_export_batch_size"` // 默认512
+	ExportTimeout      time.Duration `ccf:"export_timeout"`        // 默认30s
+}
+
+type Config struct {
+	ServiceName string            `ccf:"service_name"` // 资源属性service.name
+	Attributes  map[string]string `ccf:"attributes"`   // 其他资源属性，例如deployment.environment
+	SampleRatio *float64          `ccf:"sample_ratio"` // 根span的采样比例，默认1，有父span时沿用父span的采样决定
+	Batch       BatchConfig       `ccf:"batch"`
+	Exporters   []ExporterConfig  `ccf:"exporters"`
+	Global      bool              `ccf:"global"` // 设置为otel全局的TracerProvider与propagator
+}
+
+func (c *Config) resource() (*resource.Resource, error) {
+	attrs := make([]attribute.KeyValue, 0, len(c.Attributes)+1)
+	if c.ServiceName != "" {
+		attrs = append(attrs, attribute.String("service.name", c.ServiceName))
+	}
+	for k, v := range c.Attributes {
+		attrs = append(attrs, attribute.String(k, v))
+	}
+	return resource.Merge(resource.Default(), resource.NewSchemaless(attrs...))
+}
+
+func (c *Config) sampler() (sampler sdktrace.Sampler, err error) {
+	if c.SampleRatio == nil {
+		sampler = sdktrace.ParentBased(sdktrace.AlwaysSample())
+		return
+	}
+	if *c.SampleRatio < 0 || *c.SampleRatio > 1 {
+		err = fmt.Errorf("sample_ratio must be in [0, 1]: %v", *c.SampleRatio)
+		return
+	}
+	sampler = sdktrace.ParentBased(sdktrace.TraceIDRatioBased(*c.SampleRatio))
+	return
+}
+
+func (c *BatchConfig) processor(exporter sdktrace.SpanExporter) sdktrace.SpanProcessor {
+	if c.Disabled {
+		return sdktrace.NewSimpleSpanProcessor(exporter)
+	}
+	var opts []sdktrace.BatchSpanProcessorOption
+	if c.Timeout > 0 {
+		opts = append(opts, sdktrace.WithBatchTimeout(c.Timeout))
+	}
+	if c.MaxQueueSize > 0 {
+		opts = append(opts, sdktrace.WithMaxQueueSize(c.MaxQueueSize))
+	}
+	if c.MaxExportBatchSize > 0 {
+		opts = append(opts, sdktrace.WithMaxExportBatchSize(c.MaxExportBatchSize))
+	}
+	if c.ExportTimeout > 0 {
+		opts = append(opts, sdktrace.WithExportTimeout(c.ExportTimeout))
+	}
+	return sdktrace.NewBatchSpanProcessor(exporter, opts...)
+}
+
+// 导出结束后关闭文件
+type fileExporter struct {
+	sdktrace.SpanExporter
+	file *os.File
+}
+
+func (e *fileExporter) Shutdown(ctx context.Context) error {
+	return errors.Join(e.SpanExporter.Shutdown(ctx), e.file.Close())
+}
+
+func (c *ExporterConfig) build() (exporter sdktrace.SpanExporter, err error) {
+	switch c.Type {
+	case ExporterStdout:
+		opts := []stdouttrace.Option{stdouttrace.WithWriter(os.Stdout)}
+		if c.PrettyPrint {
+			opts = append(opts, stdouttrace.WithPrettyPrint())
+		}
+		return stdouttrace.New(opts...)
+	case ExporterFile:
+		if c.Path == "" {
+			err = fmt.Errorf("path is required")
+			return
+		}
+		var file *os.File
+		if file, err = os.OpenFile(c.Path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644); err != nil {
+			return
+		}
+		opts := []stdouttrace.Option{stdouttrace.WithWriter(file)}
+		if c.PrettyPrint {
+			opts = append(opts, stdouttrace.WithPrettyPrint())
+		}
+		var inner sdktrace.SpanExporter
+		if inner, err = stdouttrace.New(opts...); err != nil {
+			_ = file.Close()
+			return
+		}
+		exporter = &fileExporter{SpanExporter: inner, file: file}
+		return
+	case ExporterOTLP:
+		if c.Endpoint == "" {
+			err = fmt.Errorf("endpoint is required")
+			return
+		}
+		var opts []otlptracehttp.Option
+		if strings.Contains(c.Endpoint, "://") {
+			opts = append(opts, otlptracehttp.WithEndpointURL(c.Endpoint))
+		} else {
+			opts = append(opts, otlptracehttp.WithEndpoint(c.Endpoint))
+			if c.Insecure {
+				opts = append(opts, otlptracehttp.WithInsecure())
+			}
+		}
+		if len(c.Headers) > 0 {
+			opts = append(opts, otlptracehttp.WithHeaders(c.Headers))
+		}
+		switch c.Compression {
+		case "":
+		case "gzip":
+			opts = append(opts, otlptracehttp.WithCompression(otlptracehttp.GzipCompression))
+		default:
+			err = fmt.Errorf("unknown compression: %s", c.Compression)
+			return
+		}
+		if c.Timeout > 0 {
+			opts = append(opts, otlptracehttp.WithTimeout(c.Timeout))
+		}
+		// 只构造客户端，不会连接endpoint
+		return otlptracehttp.New(context.Background(), opts...)
+	default:
+		err = fmt.Errorf("unknown exporter type: %q", c.Type)
+		return
+	}
+}
+
+// New 按配置构造TracerProvider，extra为额外的exporter，例如测试中使用的tracetest.InMemoryExporter
+func New(cfg Config, extra ...sdktrace.SpanExporter) (tp *sdktrace.TracerProvider, err error) {
+	res, err := cfg.resource()
+	if err != nil {
+		return
+	}
+	sampler, err := cfg.sampler()
+	if err != nil {
+		return
+	}
+	exporters := make([]sdktrace.SpanExporter, 0, len(cfg.Exporters)+len(extra))
+	for i, c := range cfg.Exporters {
+		exporter, err1 := c.build()
+		if err1 != nil {
+			for _, e := range exporters {
+				_ = e.Shutdown(context.Background())
+			}
+			return nil, fmt.Errorf("exporters[%d]: %w", i, err1)
+		}
+		exporters = append(exporters, exporter)
+	}
+	exporters = append(exporters, extra...)
+
+	opts := []sdktrace.TracerProviderOption{sdktrace.WithResource(res), sdktrace.WithSampler(sampler)}
+	for _, e := range exporters {
+		opts = append(opts, sdktrace.WithSpanProcessor(cfg.Batch.processor(e)))
+	}
+	tp = sdktrace.NewTracerProvider(opts...)
+	return
+}
@@ -0,0 +1,126 @@
+package tracing
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	compcontzap "github.com/go-compcont/compcont-std/compcont-zap"
+	"github.com/go-compcont/compcont-std/compcont-zap/compcontzaptest"
+	"github.com/stretchr/testify/assert"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+	"go.uber.org/zap"
+)
+
+func TestHTTP(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	tp, err := New(Config{ServiceName: "test", Batch: BatchConfig{Disabled: true}}, exporter)
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer tp.Shutdown(context.Background())
+	obs := compcontzaptest.New(t)
+
+	var serverTrace trace.SpanContext
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /items/{id}", func(w http.ResponseWriter, r *http.Request) {
+		serverTrace = trace.SpanContextFromContext(r.Context())
+		compcontzap.FromContext(r.Context()).Info("handled")
+	})
+	srv := httptest.NewServer(Middleware(tp)(mux))
+	defer srv.Close()
+
+	client := &http.Client{Transport: Transport(tp, nil)}
+	resp, err := client.Get(srv.URL + "/items/1")
+	if !assert.NoError(t, err) {
+		return
+	}
+	resp.Body.Close()
+
+	spans := exporter.GetSpans()
+	if !assert.Len(t, spans, 2) {
+		return
+	}
+	server, clientSpan := spans[0], spans[1]
+	assert.Equal(t, "GET /items/{id}", server.Name)
+	assert.Equal(t, trace.SpanKindServer, server.SpanKind)
+	assert.Equal(t, "HTTP GET", clientSpan.Name)
+	assert.Equal(t, clientSpan.SpanContext.TraceID(), server.SpanContext.TraceID())
+	assert.Equal(t, clientSpan.SpanContext.SpanID(), server.Parent.SpanID())
+	assert.Equal(t, server.SpanContext.SpanID(), serverTrace.SpanID())
+
+	obs.AssertLogged(t, zap.InfoLevel, "handled",
+		zap.String("trace_id", server.SpanContext.TraceID().String()),
+		zap.String("span_id", server.SpanContext.SpanID().String()))
+}
+
+func TestFieldExtractor(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	tp, err := New(Config{}, exporter)
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer tp.Shutdown(context.Background())
+	obs := compcontzaptest.New(t)
+
+	ctx, span := tp.Tracer("test").Start(context.Background(), "op")
+	compcontzap.FromContext(ctx).Info("in span")
+	span.End()
+	compcontzap.FromContext(context.Background()).Info("no span")
+
+	obs.AssertLogged(t, zap.InfoLevel, "in span", zap.String("trace_id", span.SpanContext().TraceID().String()))
+	assert.NotContains(t, obs.Logs().FilterMessage("no span").All()[0].ContextMap(), "trace_id")
+}
+
+func TestFileExporter(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "spans.json")
+	ratio := 1.0
+	tp, err := New(Config{
+		SampleRatio: &ratio,
+		Attributes:  map[string]string{"deployment.environment": "test"},
+		Exporters:   []ExporterConfig{{Type: ExporterFile, Path: path}},
+	})
+	if !assert.NoError(t, err) {
+		return
+	}
+	_, span := tp.Tracer("test").Start(context.Background(), "written")
+	span.End()
+	assert.NoError(t, tp.Shutdown(context.Background()))
+
+	data, err := os.ReadFile(path)
+	assert.NoError(t, err)
+	assert.Contains(t, string(data), `"Name":"written"`)
+	assert.Contains(t, string(data), `"deployment.environment"`)
+
+	_, err = New(Config{Exporters: []ExporterConfig{{Type: "unknown"}}})
+	assert.Error(t, err)
+	invalid := 2.0
+	_, err = New(Config{SampleRatio: &invalid})
+	assert.Error(t, err)
+}
+
+func TestMiddlewareFlush(t *testing.T) {
+	tp, err := New(Config{ServiceName: "test", Batch: BatchConfig{Disabled: true}}, tracetest.NewInMemoryExporter())
+	if !assert.NoError(t, err) {
+		return
+	}
+	defer tp.Shutdown(context.Background())
+
+	handler := Middleware(tp)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		flusher, ok := w.(http.Flusher)
+		if !assert.True(t, ok, "response writer must implement http.Flusher") {
+			return
+		}
+		_, _ = w.Write([]byte("event"))
+		flusher.Flush()
+	}))
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/events", nil))
+	assert.True(t, rec.Flushed)
+	assert.Equal(t, "event", rec.Body.String())
+}
@@ -0,0 +1,168 @@
+package validate
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"time"
+
+	"github.com/go-compcont/compcont-core"
+	"github.com/go-compcont/compcont-std/container"
+	"github.com/go-compcont/compcont-std/schema"
+	"github.com/mitchellh/mapstructure"
+	"gopkg.in/yaml.v3"
+)
+
+// DecodeConfig 按照组件工厂相同的规则将原始配置解码为指定类型，返回解码后的值
+func DecodeConfig(t reflect.Type, raw any) (cfg any, err error) {
+	ptr := reflect.New(t)
+	switch v := raw.(type) {
+	case nil:
+	case map[string]any:
+		var decoder *mapstructure.Decoder
+		decoder, err = mapstructure.NewDecoder(&mapstructure.DecoderConfig{
+			TagName:     compcont.ConfigFieldTagName,
+			ErrorUnused: true,
+			ZeroFields:  true,
+			Result:      ptr.Interface(),
+			DecodeHook: mapstructure.ComposeDecodeHookFunc(
+				mapstructure.StringToTimeDurationHookFunc(),
+				mapstructure.StringToTimeHookFunc(time.RFC3339),
+			),
+		})
+		if err != nil {
+			return
+		}
+		if err = decoder.Decode(v); err != nil {
+			return
+		}
+	default:
+		rv := reflect.ValueOf(raw)
+		if !rv.Type().AssignableTo(t) {
+			err = fmt.Errorf("unexpected config type %s, expected %s", rv.Type(), t)
+			return
+		}
+		ptr.Elem().Set(rv)
+	}
+	cfg = ptr.Elem().Interface()
+	return
+}
+
+// 校验组件的配置，configType为空时使用工厂声明的配置类型，返回配置是否可用
+func (v *Validator) checkConfig(comp *Component, typeID compcont.ComponentTypeID, configType reflect.Type, configNode, typeNode *yaml.Node) bool {
+	c := comp.Container
+	factory, err := v.registry.GetFactory(typeID)
+	if err != nil {
+		v.errorf(c, comp, typeNode, "%w", err)
+		return false
+	}
+	if configType == nil || configType.Kind() == reflect.Interface {
+		var ok bool
+		if configType, ok = schema.ConfigTypeOf(factory); !ok {
+			return true // 无法获取配置类型，不做进一步校验
+		}
+	}
+
+	var raw any
+	if configNode != nil {
+		if err := configNode.Decode(&raw); err != nil {
+			v.errorf(c, comp, configNode, "%w", err)
+			return false
+		}
+	}
+	if _, err := DecodeConfig(configType, raw); err != nil {
+		pos := configNode
+		if pos == nil {
+			pos = typeNode
+		}
+		v.errorf(c, comp, pos, "decode config of %s: %w", typeID, err)
+		return false
+	}
+	if typeID != container.InlineContainerType { // 内联容器的子组件由loadComponents单独校验
+		v.walkConfig(comp, configType, configNode, "config")
+	}
+	return true
+}
+
+// 沿着配置类型遍历配置节点，收集嵌套组件配置中的refer并校验匿名组件
+func (v *Validator) walkConfig(comp *Component, t reflect.Type, node *yaml.Node, field string) {
+	if node == nil || node.Tag == "!!null" {
+		return
+	}
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if ok, configType := schema.IsComponentConfig(t); ok {
+		var cfg compcont.ComponentConfig
+		if err := node.Decode(&cfg); err != nil {
+			v.errorf(comp.Container, comp, node, "%s: %w", field, err)
+			return
+		}
+		if cfg.Type == "" {
+			if cfg.Refer == "" {
+				v.errorf(comp.Container, comp, node, "%s: %w, type && refer are empty", field, compcont.ErrComponentConfigInvalid)
+				return
+			}
+			comp.Refs = append(comp.Refs, &Ref{
+				Field: field,
+				Refer: cfg.Refer,
+				Node:  valueNode(node, "refer"),
+			})
+			return
+		}
+		configNode := valueNode(node, "config")
+		if v.checkConfig(comp, cfg.Type, configType, configNode, keyNode(node, "type", node)) && cfg.Type == container.InlineContainerType {
+			// 匿名的内联容器，子组件同样需要校验
+			anonymous := &Component{Config: cfg, Container: comp.Container, Field: field, Holder: comp, Node: node}
+			anonymous.Children = &Container{File: comp.Container.File, Owner: anonymous}
+			if components := valueNode(configNode, "components"); components != nil {
+				v.loadComponents(anonymous.Children, components)
+			}
+			comp.Anonymous = append(comp.Anonymous, anonymous)
+		}
+		return
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		if node.Kind != yaml.MappingNode {
+			return
+		}
+		v.walkStruct(comp, t, node, field)
+	case reflect.Slice, reflect.Array:
+		if node.Kind != yaml.SequenceNode {
+			return
+		}
+		for i, item := range node.Content {
+			v.walkConfig(comp, t.Elem(), item, field+"["+strconv.Itoa(i)+"]")
+		}
+	case reflect.Map:
+		if node.Kind != yaml.MappingNode {
+			return
+		}
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			v.walkConfig(comp, t.Elem(), node.Content[i+1], field+"."+node.Content[i].Value)
+		}
+	}
+}
+
+func (v *Validator) walkStruct(comp *Component, t reflect.Type, node *yaml.Node, field string) {
+	for i := range t.NumField() {
+		f := t.Field(i)
+		key, squash, skip := schema.FieldKey(f)
+		if skip {
+			continue
+		}
+		if squash {
+			ft := f.Type
+			if ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				v.walkStruct(comp, ft, node, field)
+				continue
+			}
+		}
+		v.walkConfig(comp, f.Type, valueNode(node, key), field+"."+key)
+	}
+}
@@ -0,0 +1,220 @@
+package validate
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/go-compcont/compcont-core"
+	"gopkg.in/yaml.v3"
+)
+
+// 静态解析得到的容器
+type Container struct {
+	File       string       // 容器配置所在文件
+	Owner      *Component   // 容器自身对应的组件，根容器为nil
+	Components []*Component // 按照配置中声明的顺序排列
+
+	byName map[compcont.ComponentName]*Component
+}
+
+func (c *Container) Get(name compcont.ComponentName) (comp *Component, ok bool) {
+	comp, ok = c.byName[name]
+	return
+}
+
+func (c *Container) Parent() *Container {
+	if c.Owner == nil {
+		return nil
+	}
+	return c.Owner.Container
+}
+
+// 静态解析得到的组件
+type Component struct {
+	Config    compcont.ComponentConfig
+	Container *Container   // 组件所在容器
+	Children  *Container   // 组件为嵌套容器时的子容器
+	Target    *Component   // 引用组件所指向的组件
+	Refs      []*Ref       // 配置中通过refer引用的其他组件
+	Anonymous []*Component // 配置中嵌套的匿名内联容器
+	Field     string       // 匿名内联容器在所属组件配置中的字段路径
+	Holder    *Component   // 匿名内联容器所属的组件
+	Node      *yaml.Node   // 组件配置在文件中的位置
+}
+
+// 组件配置中对其他组件的引用
+type Ref struct {
+	Field  string     // 引用在配置中的字段路径
+	Refer  string     // 引用路径
+	Target *Component // 解析后指向的组件
+	Node   *yaml.Node
+}
+
+func (c *Component) AbsolutePath() (path []compcont.ComponentName) {
+	for comp := c; comp != nil; comp = comp.Container.Owner {
+		path = append(path, comp.Config.Name)
+	}
+	slices.Reverse(path)
+	return
+}
+
+// Resolve 沿着引用链找到最终被引用的组件
+func (c *Component) Resolve() *Component {
+	seen := map[*Component]bool{}
+	comp := c
+	for comp.Target != nil && !seen[comp] {
+		seen[comp] = true
+		comp = comp.Target
+	}
+	return comp
+}
+
+// 解析容器树中所有的refer
+func (v *Validator) resolve(root *Container) {
+	v.resolving = map[*Component]bool{}
+	v.resolved = map[*Component]bool{}
+	var walk func(c *Container)
+	walk = func(c *Container) {
+		for _, comp := range c.Components {
+			if comp.Config.Type == "" && comp.Config.Refer != "" {
+				v.resolveComponent(comp)
+			}
+			for _, ref := range comp.Refs {
+				ref.Target = v.resolveRefer(comp, ref.Refer, ref.Node)
+			}
+		}
+		for _, comp := range c.Components {
+			if comp.Children != nil {
+				walk(comp.Children)
+			}
+			for _, anonymous := range comp.Anonymous {
+				walk(anonymous.Children)
+			}
+		}
+	}
+	walk(root)
+}
+
+// 解析引用组件的指向，结果缓存在Target中
+func (v *Validator) resolveComponent(comp *Component) *Component {
+	if comp.Target != nil || v.resolved[comp] {
+		return comp.Target
+	}
+	node := valueNode(comp.Node, "refer")
+	if v.resolving[comp] {
+		v.errorf(comp.Container, comp, node, "refer %q: circular refer", comp.Config.Refer)
+		v.resolved[comp] = true
+		return nil
+	}
+	v.resolving[comp] = true
+	comp.Target = v.resolveRefer(comp, comp.Config.Refer, node)
+	delete(v.resolving, comp)
+	v.resolved[comp] = true
+	return comp.Target
+}
+
+// 按照容器的查找规则解析refer路径，并检查被引用组件在构造comp时已经完成构造
+func (v *Validator) resolveRefer(comp *Component, refer string, node *yaml.Node) (target *Component) {
+	if node == nil {
+		node = comp.Node
+	}
+	fail := func(format string, args ...any) *Component {
+		v.errorf(comp.Container, comp, node, "refer %q: "+format, append([]any{refer}, args...)...)
+		return nil
+	}
+
+	parts := strings.Split(refer, "/")
+	current := comp.Container
+	if parts[0] == "" { // 绝对路径
+		for current.Parent() != nil {
+			current = current.Parent()
+		}
+		parts = parts[1:]
+	}
+
+	first := true
+	for i, p := range parts {
+		switch p {
+		case ".":
+			continue
+		case "..":
+			if current = current.Parent(); current == nil {
+				return fail("beyond the root container")
+			}
+			continue
+		}
+		name := compcont.ComponentName(p)
+		if !name.Validate() {
+			return fail("%w", compcont.ErrComponentNameInvalid)
+		}
+		found, ok := current.Get(name)
+		if !ok {
+			return fail("%w, name: %s", compcont.ErrComponentNameNotFound, name)
+		}
+		if first {
+			if err := checkBuildOrder(comp, current, found); err != nil {
+				return fail("%w", err)
+			}
+			first = false
+		}
+		if i == len(parts)-1 {
+			return found
+		}
+		if found.Config.Type == "" && found.Config.Refer != "" {
+			// 路径中间经过了引用组件，需要先解析其指向
+			if v.resolveComponent(found) == nil {
+				return nil
+			}
+		}
+		next := found.Resolve()
+		if next.Children == nil {
+			return fail("refer path error, %s is not a container", name)
+		}
+		current = next.Children
+	}
+	return fail("refer path is empty")
+}
+
+// 容器按deps的拓扑序构造组件，引用某个容器中的组件时，
+// 引用方在该容器中的祖先组件必须(直接或间接)依赖被引用的组件
+func checkBuildOrder(comp *Component, c *Container, target *Component) error {
+	ancestor := comp
+	for ancestor != nil && ancestor.Container != c {
+		ancestor = ancestor.Container.Owner
+	}
+	if ancestor == nil { // 引用路径不在引用方的祖先容器中，构造顺序由其他组件保证
+		return nil
+	}
+	// 匿名内联容器随所属组件一起构造
+	for ancestor.Holder != nil {
+		ancestor = ancestor.Holder
+	}
+	if ancestor == target {
+		return fmt.Errorf("%s is still being built when referred", target.Config.Name)
+	}
+	if !dependsOn(c, ancestor, target.Config.Name) {
+		return fmt.Errorf("%s must depend on %s (directly or indirectly) to refer it", ancestor.Config.Name, target.Config.Name)
+	}
+	return nil
+}
+
+func dependsOn(c *Container, comp *Component, name compcont.ComponentName) bool {
+	seen := map[compcont.ComponentName]bool{}
+	queue := slices.Clone(comp.Config.Deps)
+	for len(queue) > 0 {
+		dep := queue[0]
+		queue = queue[1:]
+		if dep == name {
+			return true
+		}
+		if seen[dep] {
+			continue
+		}
+		seen[dep] = true
+		if next, ok := c.Get(dep); ok {
+			queue = append(queue, next.Config.Deps...)
+		}
+	}
+	return false
+}
@@ -0,0 +1,331 @@
+package validate
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/go-compcont/compcont-core"
+	"github.com/go-compcont/compcont-std/container"
+	"gopkg.in/yaml.v3"
+)
+
+// 一条带有位置信息的校验错误
+type Error struct {
+	File   string
+	Line   int
+	Column int
+	Path   []compcont.ComponentName // 出错组件的绝对路径，容器级别的错误为空
+	Err    error
+}
+
+func (e *Error) Error() string {
+	var sb strings.Builder
+	sb.WriteString(e.File)
+	if e.Line > 0 {
+		fmt.Fprintf(&sb, ":%d:%d", e.Line, e.Column)
+	}
+	if len(e.Path) > 0 {
+		sb.WriteString(": ")
+		sb.WriteString(container.Path(e.Path))
+	}
+	sb.WriteString(": ")
+	sb.WriteString(e.Err.Error())
+	return sb.String()
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// 一次校验发现的全部错误
+type Errors []*Error
+
+func (e Errors) Error() string {
+	lines := make([]string, 0, len(e))
+	for _, err := range e {
+		lines = append(lines, err.Error())
+	}
+	return strings.Join(lines, "\n")
+}
+
+type Validator struct {
+	registry compcont.IFactoryRegistry
+	errs     Errors
+	warnings Errors   // 不影响运行但可能是配置失误的问题
+	imports  []string // 当前正在解析的导入文件栈，用于检测循环导入
+
+	resolving map[*Component]bool
+	resolved  map[*Component]bool
+}
+
+func New(registry compcont.IFactoryRegistry) *Validator {
+	if registry == nil {
+		registry = compcont.DefaultFactoryRegistry
+	}
+	return &Validator{registry: registry}
+}
+
+// ValidateFile 对组件配置文件做静态校验，不会构造任何组件实例。
+// 返回解析后的容器树，若存在错误，err为Errors
+func (v *Validator) ValidateFile(file string) (root *Container, err error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return
+	}
+	return v.ValidateBytes(file, data)
+}
+
+// ValidateBytes 同ValidateFile，file仅用于错误信息中的位置
+func (v *Validator) ValidateBytes(file string, data []byte) (root *Container, err error) {
+	v.errs = nil
+	v.warnings = nil
+	v.imports = []string{absFile(file)}
+	root = &Container{File: file}
+	v.parseContainer(root, data)
+	v.resolve(root)
+	if len(v.errs) > 0 {
+		err = v.errs
+	}
+	return
+}
+
+func absFile(file string) string {
+	abs, err := filepath.Abs(file)
+	if err != nil {
+		return file
+	}
+	return abs
+}
+
+// Warnings 最近一次校验发现的警告，警告不会使校验失败
+func (v *Validator) Warnings() Errors {
+	return v.warnings
+}
+
+func (v *Validator) errorf(c *Container, comp *Component, node *yaml.Node, format string, args ...any) {
+	v.errs = append(v.errs, newError(c, comp, node, format, args...))
+}
+
+func (v *Validator) warnf(c *Container, comp *Component, node *yaml.Node, format string, args ...any) {
+	v.warnings = append(v.warnings, newError(c, comp, node, format, args...))
+}
+
+func newError(c *Container, comp *Component, node *yaml.Node, format string, args ...any) (e *Error) {
+	e = &Error{Err: fmt.Errorf(format, args...)}
+	if c != nil {
+		e.File = c.File
+	}
+	if comp != nil {
+		e.Path = comp.AbsolutePath()
+	}
+	if node != nil {
+		e.Line, e.Column = node.Line, node.Column
+	}
+	return
+}
+
+func (v *Validator) parseContainer(c *Container, data []byte) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		v.errorf(c, nil, nil, "parse error: %w", err)
+		return
+	}
+	if len(doc.Content) == 0 { // 空文件
+		return
+	}
+	v.loadComponents(c, doc.Content[0])
+}
+
+// 对应LoadNamedComponents，校验一批具名组件
+func (v *Validator) loadComponents(c *Container, node *yaml.Node) {
+	if node.Kind != yaml.SequenceNode {
+		v.errorf(c, nil, node, "component list must be a sequence")
+		return
+	}
+	c.byName = map[compcont.ComponentName]*Component{}
+	for _, item := range node.Content {
+		comp := v.parseComponent(c, item)
+		if comp == nil {
+			continue
+		}
+		if !comp.Config.Name.Validate() {
+			v.errorf(c, comp, keyNode(item, "name", item), "%w, name: %q", compcont.ErrComponentNameInvalid, comp.Config.Name)
+		} else if _, ok := c.byName[comp.Config.Name]; ok {
+			v.errorf(c, comp, keyNode(item, "name", item), "%w, name: %s", compcont.ErrComponentAlreadyExists, comp.Config.Name)
+		} else {
+			c.byName[comp.Config.Name] = comp
+		}
+		c.Components = append(c.Components, comp)
+	}
+
+	for _, comp := range c.Components {
+		for i, dep := range comp.Config.Deps {
+			if _, ok := c.byName[dep]; !ok {
+				v.errorf(c, comp, seqItem(valueNode(comp.Node, "deps"), i, comp.Node), "%w, dependency %s not found", compcont.ErrComponentDependencyNotFound, dep)
+			}
+		}
+	}
+	v.checkCycles(c)
+
+	for _, comp := range c.Components {
+		v.checkComponent(comp)
+	}
+}
+
+var componentKeys = []string{"name", "type", "refer", "deps", "config"}
+
+func (v *Validator) parseComponent(c *Container, node *yaml.Node) (comp *Component) {
+	if node.Kind != yaml.MappingNode {
+		v.errorf(c, nil, node, "component config must be a mapping")
+		return
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key := node.Content[i]
+		if !slices.Contains(componentKeys, key.Value) {
+			v.errorf(c, nil, key, "unknown component field %q", key.Value)
+		}
+	}
+	comp = &Component{Container: c, Node: node}
+	if err := node.Decode(&comp.Config); err != nil {
+		v.errorf(c, nil, node, "%w", err)
+		return nil
+	}
+	return
+}
+
+// 检查组件自身的类型、配置与嵌套容器
+func (v *Validator) checkComponent(comp *Component) {
+	c := comp.Container
+	cfg := comp.Config
+	if cfg.Type == "" {
+		if cfg.Refer == "" {
+			v.errorf(c, comp, comp.Node, "%w, type && refer are empty", compcont.ErrComponentConfigInvalid)
+		}
+		return
+	}
+	if cfg.Refer != "" {
+		// 运行时以type为准，不影响加载
+		v.warnf(c, comp, valueNode(comp.Node, "refer"), "type and refer are both set, refer is ignored")
+	}
+
+	configNode := valueNode(comp.Node, "config")
+	if !v.checkConfig(comp, cfg.Type, nil, configNode, keyNode(comp.Node, "type", comp.Node)) {
+		return
+	}
+
+	switch cfg.Type {
+	case container.InlineContainerType:
+		comp.Children = &Container{File: c.File, Owner: comp}
+		components := valueNode(configNode, "components")
+		if components == nil {
+			return
+		}
+		v.loadComponents(comp.Children, components)
+	case container.ContainerImportType:
+		fromFile := valueNode(configNode, "from_file")
+		if fromFile == nil {
+			v.errorf(c, comp, configNode, "from_file is required")
+			return
+		}
+		comp.Children = &Container{File: fromFile.Value, Owner: comp}
+		abs := absFile(fromFile.Value)
+		if slices.Contains(v.imports, abs) {
+			v.errorf(c, comp, fromFile, "import cycle: %s", strings.Join(append(v.imports, abs), " -> "))
+			return
+		}
+		data, err := os.ReadFile(fromFile.Value)
+		if err != nil {
+			v.errorf(c, comp, fromFile, "%w", err)
+			return
+		}
+		if !strings.HasSuffix(fromFile.Value, ".json") && !strings.HasSuffix(fromFile.Value, ".yml") && !strings.HasSuffix(fromFile.Value, ".yaml") {
+			v.errorf(c, comp, fromFile, "unsupported config file format: %s", fromFile.Value)
+			return
+		}
+		v.imports = append(v.imports, abs)
+		v.parseContainer(comp.Children, data)
+		v.imports = v.imports[:len(v.imports)-1]
+	}
+}
+
+// 对每个容器的deps做环检测
+func (v *Validator) checkCycles(c *Container) {
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+	state := map[compcont.ComponentName]int{}
+	var stack []compcont.ComponentName
+	var visit func(comp *Component)
+	visit = func(comp *Component) {
+		name := comp.Config.Name
+		state[name] = visiting
+		stack = append(stack, name)
+		for _, dep := range comp.Config.Deps {
+			next, ok := c.byName[dep]
+			if !ok {
+				continue
+			}
+			switch state[dep] {
+			case unvisited:
+				visit(next)
+			case visiting:
+				idx := 0
+				for i, n := range stack {
+					if n == dep {
+						idx = i
+					}
+				}
+				cycle := append(append([]compcont.ComponentName{}, stack[idx:]...), dep)
+				parts := make([]string, 0, len(cycle))
+				for _, n := range cycle {
+					parts = append(parts, string(n))
+				}
+				v.errorf(c, comp, valueNode(comp.Node, "deps"), "%w: %s", compcont.ErrCircularDependency, strings.Join(parts, " -> "))
+			}
+		}
+		stack = stack[:len(stack)-1]
+		state[name] = visited
+	}
+	for _, comp := range c.Components {
+		if c.byName[comp.Config.Name] == comp && state[comp.Config.Name] == unvisited {
+			visit(comp)
+		}
+	}
+}
+
+// 获取mapping节点中key对应的值节点
+func valueNode(node *yaml.Node, key string) *yaml.Node {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
+
+// 获取mapping节点中的key节点，不存在时返回fallback
+func keyNode(node *yaml.Node, key string, fallback *yaml.Node) *yaml.Node {
+	if node != nil && node.Kind == yaml.MappingNode {
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			if node.Content[i].Value == key {
+				return node.Content[i]
+			}
+		}
+	}
+	return fallback
+}
+
+func seqItem(node *yaml.Node, i int, fallback *yaml.Node) *yaml.Node {
+	if node == nil || node.Kind != yaml.SequenceNode || i >= len(node.Content) {
+		return fallback
+	}
+	return node.Content[i]
+}
@@ -0,0 +1,148 @@
+package validate
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/go-compcont/compcont-core"
+	"github.com/go-compcont/compcont-std/container"
+	"github.com/stretchr/testify/assert"
+)
+
+type echoConfig struct {
+	Message string                                              `ccf:"message"`
+	Next    *compcont.TypedComponentConfig[any, any]            `ccf:"next"`
+	Others  []compcont.TypedComponentConfig[echoConfig, string] `ccf:"others"`
+}
+
+var echoFactory = &compcont.TypedSimpleComponentFactory[echoConfig, any]{
+	TypeID: "echo",
+	CreateInstanceFunc: func(ctx compcont.BuildContext, config echoConfig) (instance any, err error) {
+		panic("dry-run must not construct components")
+	},
+}
+
+func newRegistry() compcont.IFactoryRegistry {
+	r := compcont.NewFactoryRegistry()
+	compcont.MustRegister(r, echoFactory)
+	container.MustRegisterContainerInline(r)
+	container.MustRegisterContainerImport(r)
+	return r
+}
+
+const validYaml = `
+- name: a
+  type: echo
+  config: { message: "a" }
+- { name: b, deps: [a], refer: a }
+- name: c
+  type: std.container-inline
+  deps: [b]
+  config:
+    components:
+      - name: d
+        type: echo
+        config:
+          next: { refer: "../b" }
+          others:
+            - { type: echo, config: { message: "nested" } }
+      - { name: e, deps: [d], refer: "./d" }
+`
+
+func TestValidate(t *testing.T) {
+	root, err := New(newRegistry()).ValidateBytes("valid.yaml", []byte(validYaml))
+	assert.NoError(t, err)
+
+	b, _ := root.Get("b")
+	a, _ := root.Get("a")
+	assert.Same(t, a, b.Target)
+
+	c, _ := root.Get("c")
+	d, ok := c.Children.Get("d")
+	assert.True(t, ok)
+	assert.Equal(t, []compcont.ComponentName{"c", "d"}, d.AbsolutePath())
+	if assert.Len(t, d.Refs, 1) {
+		assert.Equal(t, "config.next", d.Refs[0].Field)
+		assert.Same(t, b, d.Refs[0].Target)
+		assert.Same(t, a, d.Refs[0].Target.Resolve())
+	}
+}
+
+const invalidYaml = `
+- name: a
+  type: echo
+  deps: [b]
+  config: { message: "a", unknown: 1 }
+- name: b
+  type: missing
+  deps: [a]
+- { name: c, refer: a }
+- { name: d, deps: [x], refer: "a/e" }
+- name: inline
+  type: std.container-inline
+  config:
+    components:
+      - { name: f, type: echo, config: { next: { refer: "../inline" } } }
+`
+
+func TestValidateErrors(t *testing.T) {
+	_, err := New(newRegistry()).ValidateBytes("invalid.yaml", []byte(invalidYaml))
+	var errs Errors
+	if !assert.ErrorAs(t, err, &errs) {
+		return
+	}
+	for _, e := range errs {
+		t.Log(e)
+		assert.Equal(t, "invalid.yaml", e.File)
+		assert.NotZero(t, e.Line)
+	}
+	assert.True(t, containsErr(errs, compcont.ErrCircularDependency))
+	assert.True(t, containsErr(errs, compcont.ErrComponentTypeNotRegistered))
+	assert.True(t, containsErr(errs, compcont.ErrComponentDependencyNotFound))
+	// 另有解码错误、c和d缺少对a的依赖、f引用了正在构造的容器
+	assert.Len(t, errs, 7)
+}
+
+func containsErr(errs Errors, target error) bool {
+	for _, e := range errs {
+		if errors.Is(e, target) {
+			return true
+		}
+	}
+	return false
+}
+
+type holderConfig struct {
+	Inner *compcont.TypedComponentConfig[any, any] `ccf:"inner"`
+}
+
+const anonymousYaml = `
+- { name: a, type: echo, refer: x }
+- name: b
+  type: holder
+  config:
+    inner:
+      type: std.container-inline
+      config:
+        components:
+          - { name: c, type: echo, config: { unknown: 1 } }
+          - { name: d, type: missing }
+`
+
+func TestValidateWarningsAndAnonymousContainer(t *testing.T) {
+	r := newRegistry()
+	compcont.MustRegister(r, &compcont.TypedSimpleComponentFactory[holderConfig, any]{TypeID: "holder"})
+	v := New(r)
+	_, err := v.ValidateBytes("anonymous.yaml", []byte(anonymousYaml))
+	var errs Errors
+	if !assert.ErrorAs(t, err, &errs) {
+		return
+	}
+	// 匿名内联容器中子组件的解码错误与未注册类型
+	assert.Len(t, errs, 2)
+	assert.True(t, containsErr(errs, compcont.ErrComponentTypeNotRegistered))
+	// type与refer同时设置只是警告
+	if assert.Len(t, v.Warnings(), 1) {
+		assert.Equal(t, []compcont.ComponentName{"a"}, v.Warnings()[0].Path)
+	}
+}